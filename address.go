@@ -0,0 +1,111 @@
+package gohome
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrInvalidAddress is returned when an ambient, light point or group number is outside the OpenWebNet range
+var ErrInvalidAddress = errors.New("invalid OWN address")
+
+//localBus is the first WHERE parameter of the addresses on a private riser: <where>#4#<interface>
+const localBus = "4"
+
+//groupPrefix introduces a group in a WHERE description: group:<name>
+const groupPrefix = "group:"
+
+//address is a decoded OWN WHERE code
+type address struct {
+	general bool
+	group   int
+	ambient int
+	point   int
+}
+
+//ambientCode returns the WHERE code of an ambient: 00, 1-9, 100
+func ambientCode(amb int) (string, error) {
+	switch {
+	case amb == 0:
+		return "00", nil
+	case amb >= 1 && amb <= 9:
+		return strconv.Itoa(amb), nil
+	case amb == 10:
+		return "100", nil
+	}
+	return "", errors.Wrapf(ErrInvalidAddress, "ambient %d is not in 0-10", amb)
+}
+
+//pointCode returns the WHERE code of a light point: AP when both are 1-9, AAPP otherwise (A=00-10, PL=01-15)
+func pointCode(amb int, pl int) (string, error) {
+	if amb < 0 || amb > 10 || pl < 1 || pl > 15 {
+		return "", errors.Wrapf(ErrInvalidAddress, "point %d of ambient %d is not in A=0-10, PL=1-15", pl, amb)
+	}
+	if amb >= 1 && amb <= 9 && pl <= 9 {
+		return fmt.Sprintf("%d%d", amb, pl), nil
+	}
+	return fmt.Sprintf("%02d%02d", amb, pl), nil
+}
+
+//groupCode returns the WHERE code of a group: #1-#255
+func groupCode(group int) (string, error) {
+	if group < 1 || group > 255 {
+		return "", errors.Wrapf(ErrInvalidAddress, "group %d is not in 1-255", group)
+	}
+	return fmt.Sprintf("#%d", group), nil
+}
+
+//parseAddress decodes a WHERE code without parameters
+func parseAddress(code string) (address, error) {
+	addr := address{}
+	invalid := errors.Wrapf(ErrInvalidAddress, "where: %s", code)
+	if strings.HasPrefix(code, "#") {
+		g, err := strconv.Atoi(code[1:])
+		if err != nil || g < 1 || g > 255 {
+			return addr, invalid
+		}
+		addr.group = g
+		return addr, nil
+	}
+	n, err := strconv.Atoi(code)
+	if err != nil || n < 0 {
+		return addr, invalid
+	}
+	switch len(code) {
+	case 1:
+		addr.general = n == 0
+		addr.ambient = n
+	case 2:
+		if code == "00" {
+			addr.ambient = 0
+			break
+		}
+		addr.ambient, addr.point = n/10, n%10
+		if addr.ambient == 0 || addr.point == 0 {
+			return addr, invalid
+		}
+	case 3:
+		if code != "100" {
+			return addr, invalid
+		}
+		addr.ambient = 10
+	case 4:
+		addr.ambient, addr.point = n/100, n%100
+		if addr.ambient > 10 || addr.point < 1 || addr.point > 15 {
+			return addr, invalid
+		}
+	default:
+		return addr, invalid
+	}
+	return addr, nil
+}
+
+//busInterface returns the interface of a private riser address (<where>#4#<interface>), empty when on the main bus
+func busInterface(params []string) string {
+	if len(params) == 2 && params[0] == localBus {
+		return params[1]
+	}
+	return ""
+}
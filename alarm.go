@@ -0,0 +1,194 @@
+package gohome
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrAlarmZoneNotFound is returned when the desired alarm zone is not found in the conf file
+var ErrAlarmZoneNotFound = errors.New("alarm zone not found")
+
+//ErrNotAlarmEvent is returned when a message does not come from the burglar alarm
+var ErrNotAlarmEvent = errors.New("message is not an alarm event")
+
+//alarmPrefix introduces a burglar alarm zone in a WHERE description: alarm:<name>
+const alarmPrefix = "alarm:"
+
+//Categories of the alarm events
+const ALARM_SYSTEM = "SYSTEM"
+const ALARM_BATTERY = "BATTERY"
+const ALARM_NETWORK = "NETWORK"
+const ALARM_ZONE = "ZONE"
+const ALARM_ALARM = "ALARM"
+
+var alarmCategories = map[string]string{
+	"MAINTENANCE":           ALARM_SYSTEM,
+	"ACTIVATION":            ALARM_SYSTEM,
+	"DEACTIVATION":          ALARM_SYSTEM,
+	"DELAY_END":             ALARM_SYSTEM,
+	"ENGAGED":               ALARM_SYSTEM,
+	"DISENGAGED":            ALARM_SYSTEM,
+	"BATTERY_FAULT":         ALARM_BATTERY,
+	"BATTERY_OK":            ALARM_BATTERY,
+	"BATTERY_UNLOADED":      ALARM_BATTERY,
+	"NO_NETWORK":            ALARM_NETWORK,
+	"NETWORK_OK":            ALARM_NETWORK,
+	"NO_RECEPTION":          ALARM_NETWORK,
+	"ZONE_ENGAGED":          ALARM_ZONE,
+	"ZONE_DIVIDED":          ALARM_ZONE,
+	"TECHNICAL_ALARM":       ALARM_ALARM,
+	"TECHNICAL_ALARM_RESET": ALARM_ALARM,
+	"INTRUSION_ALARM":       ALARM_ALARM,
+	"TAMPERING_ALARM":       ALARM_ALARM,
+	"ANTI_PANIC_ALARM":      ALARM_ALARM,
+	"SILENT_ALARM":          ALARM_ALARM,
+}
+
+//AlarmEvent is an event of the burglar alarm central. Zone is -1 for the events of the whole system.
+type AlarmEvent struct {
+	Category string
+	State    string
+	Where    Where
+	Zone     int
+}
+
+//AlarmStatus is the state of the burglar alarm built from its events. Zones are keyed by zone number.
+type AlarmStatus struct {
+	Engaged     bool
+	Maintenance bool
+	Battery     string
+	Network     string
+	Zones       map[string]string
+	Alarms      []AlarmEvent
+}
+
+//NewAlarmEvent decodes a message of the burglar alarm
+func NewAlarmEvent(msg Message) (AlarmEvent, error) {
+	if msg.Who == nil || msg.Who.Code != whoAlarm().Code || msg.Kind != COMMAND {
+		return AlarmEvent{}, ErrNotAlarmEvent
+	}
+	category, ok := alarmCategories[msg.What.Desc]
+	if !ok {
+		return AlarmEvent{}, errors.Wrapf(ErrNotAlarmEvent, "unknown alarm state %s", msg.What.Desc)
+	}
+	event := AlarmEvent{Category: category, State: msg.What.Desc, Where: msg.Where, Zone: -1}
+	if strings.HasPrefix(msg.Where.Code, "#") {
+		zone, err := strconv.Atoi(msg.Where.Code[1:])
+		if err == nil {
+			event.Zone = zone
+		}
+	} else if len(msg.Where.Code) == 2 {
+		event.Zone = int(msg.Where.Code[0] - '0')
+	}
+	return event, nil
+}
+
+//IsAlarm returns true if the event reports an intrusion, tampering, panic or technical alarm
+func (e AlarmEvent) IsAlarm() bool {
+	return e.Category == ALARM_ALARM && e.State != "TECHNICAL_ALARM_RESET"
+}
+
+//String returns a human readable description of the event
+func (e AlarmEvent) String() string {
+	if e.Zone < 0 {
+		return fmt.Sprintf("%s %s", e.Category, e.State)
+	}
+	return fmt.Sprintf("%s %s %s", e.Category, e.State, e.Where.Desc)
+}
+
+//Update updates the status with an alarm event
+func (s *AlarmStatus) Update(e AlarmEvent) {
+	if s.Zones == nil {
+		s.Zones = map[string]string{}
+	}
+	switch e.Category {
+	case ALARM_SYSTEM:
+		switch e.State {
+		case "ENGAGED", "ACTIVATION":
+			s.Engaged = true
+		case "DISENGAGED", "DEACTIVATION":
+			s.Engaged = false
+		case "MAINTENANCE":
+			s.Maintenance = true
+		}
+	case ALARM_BATTERY:
+		s.Battery = e.State
+	case ALARM_NETWORK:
+		s.Network = e.State
+	case ALARM_ZONE:
+		zone := e.Where.Desc
+		if e.Zone >= 0 {
+			zone = strconv.Itoa(e.Zone)
+		}
+		s.Zones[zone] = e.State
+	case ALARM_ALARM:
+		s.Alarms = append(s.Alarms, e)
+	}
+}
+
+//alarmCode returns the WHERE code of the alarm zone with the given name, #Z, or of a sensor of the zone,
+//<zone>.<sensor> as decoded by alarmFromCode, ZN
+func (p *Plant) alarmCode(name string) (string, error) {
+	split := strings.Split(name, ".")
+	zone, ok := p.Alarms[split[0]]
+	if !ok || len(split) > 2 {
+		return "", ErrAlarmZoneNotFound
+	}
+	if zone < 0 || zone > 9 {
+		return "", errors.Wrapf(ErrInvalidAddress, "alarm zone %d is not in 0-9", zone)
+	}
+	if len(split) == 1 {
+		return fmt.Sprintf("#%d", zone), nil
+	}
+	sensor, err := strconv.Atoi(split[1])
+	if err != nil || sensor < 0 || sensor > 9 || len(split[1]) != 1 {
+		return "", errors.Wrapf(ErrInvalidAddress, "alarm sensor %s is not in 0-9", split[1])
+	}
+	return fmt.Sprintf("%d%d", zone, sensor), nil
+}
+
+//alarmFromCode decodes the WHERE of an alarm frame: #Z for zone Z, ZN for the sensor N of zone Z
+func (p *Plant) alarmFromCode(code string) (Where, error) {
+	if code == "" {
+		return Where{}, nil
+	}
+	code, params := splitParams(code)
+	where := Where{Code: code, Params: params}
+	zone, err := strconv.Atoi(strings.TrimPrefix(code, "#"))
+	if err != nil {
+		return Where{}, errors.Wrapf(ErrWhereNotInPlant, "where: %v", code)
+	}
+	sensor := ""
+	if !strings.HasPrefix(code, "#") {
+		if len(code) != 2 {
+			return where, nil
+		}
+		zone, sensor = zone/10, "."+code[1:]
+	}
+	for ka, z := range p.Alarms {
+		if z == zone {
+			where.Desc = alarmPrefix + ka + sensor
+		}
+	}
+	return where, nil
+}
+
+//AlarmStatus asks the alarm central the state of the system and of its zones
+func (h *Home) AlarmStatus() (AlarmStatus, error) {
+	status := AlarmStatus{Zones: map[string]string{}}
+	answers, err := h.Ask(NewRequest(whoAlarm(), What{}, GENERAL))
+	if err != nil {
+		return status, errors.Wrapf(err, "cannot get alarm status")
+	}
+	for _, a := range answers {
+		e, err := NewAlarmEvent(a)
+		if err != nil {
+			continue
+		}
+		status.Update(e)
+	}
+	return status, nil
+}
@@ -0,0 +1,90 @@
+package gohome_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestAlarmEvent(t *testing.T) {
+	plant := loadTestPlant(t)
+	events := map[string]gohome.AlarmEvent{
+		"*5*8*0##":   gohome.AlarmEvent{Category: gohome.ALARM_SYSTEM, State: "ENGAGED", Zone: -1},
+		"*5*4*0##":   gohome.AlarmEvent{Category: gohome.ALARM_BATTERY, State: "BATTERY_FAULT", Zone: -1},
+		"*5*11*#1##": gohome.AlarmEvent{Category: gohome.ALARM_ZONE, State: "ZONE_ENGAGED", Zone: 1, Where: gohome.Where{Desc: "alarm:perimeter"}},
+		"*5*15*#2##": gohome.AlarmEvent{Category: gohome.ALARM_ALARM, State: "INTRUSION_ALARM", Zone: 2, Where: gohome.Where{Desc: "alarm:garage"}},
+		"*5*16*13##": gohome.AlarmEvent{Category: gohome.ALARM_ALARM, State: "TAMPERING_ALARM", Zone: 1, Where: gohome.Where{Desc: "alarm:perimeter.3"}},
+	}
+	for f, exp := range events {
+		e, err := gohome.NewAlarmEvent(plant.ParseFrame(f))
+		if err != nil {
+			t.Errorf("alarm event not decoded from %s: %v", f, err)
+			continue
+		}
+		if e.Category != exp.Category || e.State != exp.State || e.Zone != exp.Zone || e.Where.Desc != exp.Where.Desc {
+			t.Errorf("wrong alarm event from %s: %+v", f, e)
+		}
+	}
+	if e, err := gohome.NewAlarmEvent(plant.ParseFrame("*1*1*11##")); err == nil {
+		t.Errorf("light frame should not be an alarm event: %+v", e)
+	}
+}
+
+func TestAlarmSensorWhere(t *testing.T) {
+	plant := loadTestPlant(t)
+	codes := map[string]string{"alarm:perimeter": "#1", "alarm:perimeter.3": "13", "alarm:garage.0": "20"}
+	for desc, code := range codes {
+		where, err := plant.WhereFromDesc(desc)
+		if err != nil || where.Code != code {
+			t.Errorf("wrong where from %s: %+v (err: %v)", desc, where, err)
+		}
+	}
+	e, err := gohome.NewAlarmEvent(plant.ParseFrame("*5*16*13##"))
+	if err != nil {
+		t.Fatalf("alarm event not decoded: %v", err)
+	}
+	if where, err := plant.WhereFromDesc(e.Where.Desc); err != nil || where.Code != "13" {
+		t.Errorf("sensor %s does not go back to 13: %+v (err: %v)", e.Where.Desc, where, err)
+	}
+	for _, desc := range []string{"alarm:perimeter.12", "alarm:perimeter.x", "alarm:perimeter.1.2"} {
+		if where, err := plant.WhereFromDesc(desc); err == nil {
+			t.Errorf("where from %s should fail: %+v", desc, where)
+		}
+	}
+}
+
+func TestAlarmStatusUpdate(t *testing.T) {
+	plant := loadTestPlant(t)
+	status := gohome.AlarmStatus{}
+	for _, f := range []string{"*5*8*0##", "*5*5*0##", "*5*11*#1##", "*5*18*#2##", "*5*15*#2##", "*5*13*#1##", "*5*11*#5##", "*5*18*#6##"} {
+		e, err := gohome.NewAlarmEvent(plant.ParseFrame(f))
+		if err != nil {
+			t.Errorf("alarm event not decoded from %s: %v", f, err)
+		}
+		status.Update(e)
+	}
+	if !status.Engaged || status.Battery != "BATTERY_OK" || status.Zones["1"] != "ZONE_ENGAGED" || status.Zones["2"] != "ZONE_DIVIDED" {
+		t.Errorf("wrong alarm status: %+v", status)
+	}
+	if status.Zones["5"] != "ZONE_ENGAGED" || status.Zones["6"] != "ZONE_DIVIDED" {
+		t.Errorf("zones not in the plant should be kept apart: %+v", status.Zones)
+	}
+	if len(status.Alarms) != 2 || !status.Alarms[0].IsAlarm() || status.Alarms[1].IsAlarm() {
+		t.Errorf("wrong alarms in status: %+v", status.Alarms)
+	}
+}
+
+func TestAlarmReadOnly(t *testing.T) {
+	plant := loadTestPlant(t)
+	h := gohome.NewHome(plant)
+	who := gohome.NewWho("ALARM")
+	what, err := who.WhatFromDesc("DISENGAGED")
+	if err != nil {
+		t.Errorf("What not found: %v", err)
+	}
+	if err := h.Do(gohome.NewCommand(who, what, gohome.GENERAL)); errors.Cause(err) != gohome.ErrReadOnly {
+		t.Errorf("alarm commands should be refused (err: %v)", err)
+	}
+}
@@ -0,0 +1,85 @@
+package gohome
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrAuthentication is returned when the gateway refuses the password of the plant
+var ErrAuthentication = errors.New("AUTHENTICATION FAILED")
+
+//ownPassword encodes the numeric password of the gateway with the nonce it sent, as in the OPEN authentication
+func ownPassword(password string, nonce string) (string, error) {
+	pass, err := strconv.ParseUint(password, 10, 32)
+	if err != nil {
+		return "", errors.Wrapf(ErrAuthentication, "password is not a number")
+	}
+	var num1, num2 uint32
+	start := true
+	for _, c := range nonce {
+		if c != '0' && start {
+			num2 = uint32(pass)
+			start = false
+		}
+		switch c {
+		case '1':
+			num1 = (num2&0xFFFFFF80)>>7 | num2<<25
+		case '2':
+			num1 = (num2&0xFFFFFFF0)>>4 | num2<<28
+		case '3':
+			num1 = (num2&0xFFFFFFF8)>>3 | num2<<29
+		case '4':
+			num1 = num2<<1 | num2>>31
+		case '5':
+			num1 = num2<<5 | num2>>27
+		case '6':
+			num1 = num2<<12 | num2>>20
+		case '7':
+			num1 = num2&0x0000FF00 | (num2&0x000000FF)<<24 | (num2&0x00FF0000)>>16 | (num2&0xFF000000)>>8
+		case '8':
+			num1 = (num2&0x0000FFFF)<<16 | num2>>24 | (num2&0x00FF0000)>>8
+		case '9':
+			num1 = ^num2
+		case '0':
+			num1 = num2
+		default:
+			return "", errors.Wrapf(ErrAuthentication, "wrong nonce %s", nonce)
+		}
+		num2 = num1
+	}
+	return strconv.FormatUint(uint64(num1), 10), nil
+}
+
+//openSession asks the gateway the session and, when it answers with a nonce, authenticates with the password
+func (c *Cable) openSession(conn *net.TCPConn, session Message) error {
+	if err := c.send(conn, session.Frame()); err != nil {
+		return errors.Wrapf(err, "cannot open session")
+	}
+	answer, err := c.receive(conn, false)
+	if err != nil {
+		return errors.Wrapf(err, "cannot open session")
+	}
+	switch {
+	case answer == SystemMessages["ACK"].Frame():
+		return nil
+	case !strings.HasPrefix(answer, "*#") || answer == SystemMessages["NACK"].Frame():
+		return ErrNAK
+	}
+	if c.password == "" {
+		return errors.Wrapf(ErrAuthentication, "the gateway asks a password")
+	}
+	pass, err := ownPassword(c.password, strings.TrimSuffix(strings.TrimPrefix(answer, "*#"), "##"))
+	if err != nil {
+		return err
+	}
+	if err := c.send(conn, "*#"+pass+"##"); err != nil {
+		return errors.Wrapf(err, "cannot send password")
+	}
+	if !c.acked(conn) {
+		return ErrAuthentication
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package gohome
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+//ErrNotShutterStatus is returned when a message does not carry the state of a shutter
+var ErrNotShutterStatus = errors.New("message is not a shutter status")
+
+//shutterStates maps the status values of dimension SHUTTER_STATUS to the automation WHATs
+var shutterStates = map[string]string{
+	"10": "STOP",
+	"11": "UP",
+	"12": "DOWN",
+}
+
+//ShutterStatus is the state of a roller shutter or blind. Level is the position in percent, -1 when unknown.
+type ShutterStatus struct {
+	Where Where
+	State string
+	Level int
+}
+
+//NewShutterStatus decodes an automation event or a SHUTTER_STATUS dimension response
+func NewShutterStatus(msg Message) (ShutterStatus, error) {
+	if msg.Who == nil || msg.Who.Code != whoAutomation().Code {
+		return ShutterStatus{}, errors.Wrapf(ErrNotShutterStatus, "WHO is not AUTOMATION")
+	}
+	switch msg.Kind {
+	case COMMAND:
+		return ShutterStatus{Where: msg.Where, State: msg.What.Desc, Level: -1}, nil
+	case DIMENSIONRESPONSE:
+		if msg.Dimension != "10" || len(msg.Values) < 2 {
+			return ShutterStatus{}, errors.Wrapf(ErrNotShutterStatus, "dimension %s with %d values", msg.Dimension, len(msg.Values))
+		}
+		state, ok := shutterStates[string(msg.Values[0])]
+		if !ok {
+			return ShutterStatus{}, errors.Wrapf(ErrNotShutterStatus, "unknown shutter state %s", msg.Values[0])
+		}
+		level, err := strconv.Atoi(string(msg.Values[1]))
+		if err != nil {
+			return ShutterStatus{}, errors.Wrapf(ErrNotShutterStatus, "wrong shutter level %s", msg.Values[1])
+		}
+		return ShutterStatus{Where: msg.Where, State: state, Level: level}, nil
+	}
+	return ShutterStatus{}, errors.Wrapf(ErrNotShutterStatus, "kind %s", msg.Kind)
+}
+
+//ShutterStatus asks the plant the state of the shutters at the given where
+func (h *Home) ShutterStatus(where Where) ([]ShutterStatus, error) {
+	answers, err := h.Ask(NewRequest(whoAutomation(), What{}, where))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get shutter status of %s", where.Desc)
+	}
+	statuses := make([]ShutterStatus, 0, len(answers))
+	for _, a := range answers {
+		s, err := NewShutterStatus(a)
+		if err != nil {
+			continue
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+//SetShutterLevel moves an advanced actuator to the given position in percent
+func (h *Home) SetShutterLevel(where Where, level int) error {
+	if level < 0 || level > 100 {
+		return errors.Errorf("shutter level %d is not in 0-100", level)
+	}
+	cmd := NewDimensionWrite(whoAutomation(), where, "11#1", Value(strconv.Itoa(level)))
+	return h.Do(cmd)
+}
@@ -0,0 +1,52 @@
+package gohome_test
+
+import (
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestAutomationCommand(t *testing.T) {
+	plant := loadTestPlant(t)
+	who := gohome.NewWho("AUTOMATION")
+	what, err := who.WhatFromDesc("UP")
+	if err != nil {
+		t.Errorf("What not found: %v", err)
+	}
+	where, err := plant.WhereFromDesc("living.window")
+	if err != nil {
+		t.Errorf("Where not found: %v", err)
+	}
+	if frame := gohome.NewCommand(who, what, where).Frame(); frame != "*2*1*25##" {
+		t.Errorf("Wrong automation command %s", frame)
+	}
+}
+
+func TestShutterStatus(t *testing.T) {
+	plant := loadTestPlant(t)
+	frames := map[string]gohome.ShutterStatus{
+		"*2*0*25##":             gohome.ShutterStatus{State: "STOP", Level: -1},
+		"*2*2*26##":             gohome.ShutterStatus{State: "DOWN", Level: -1},
+		"*#2*25*10*10*65*0*0##": gohome.ShutterStatus{State: "STOP", Level: 65},
+		"*#2*26*10*11*20*0*0##": gohome.ShutterStatus{State: "UP", Level: 20},
+	}
+	for f, exp := range frames {
+		msg := plant.ParseFrame(f)
+		s, err := gohome.NewShutterStatus(msg)
+		if err != nil {
+			t.Errorf("shutter status not decoded from %s: %v", f, err)
+			continue
+		}
+		if s.State != exp.State || s.Level != exp.Level {
+			t.Errorf("wrong shutter status from %s: %+v", f, s)
+		}
+		if s.Where.Desc != "living.window" && s.Where.Desc != "living.door" {
+			t.Errorf("wrong shutter where from %s: %s", f, s.Where.Desc)
+		}
+	}
+	for _, f := range []string{"*1*1*25##", "*#2*25*10*15*65*0*0##", "*#2*25##"} {
+		if s, err := gohome.NewShutterStatus(plant.ParseFrame(f)); err == nil {
+			t.Errorf("frame %s should not be a shutter status: %+v", f, s)
+		}
+	}
+}
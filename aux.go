@@ -0,0 +1,91 @@
+package gohome
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+//ErrAuxNotFound is returned when the desired auxiliary channel is not found in the conf file
+var ErrAuxNotFound = errors.New("auxiliary channel not found")
+
+//ErrNotAuxStatus is returned when a message does not carry the state of an auxiliary channel
+var ErrNotAuxStatus = errors.New("message is not an auxiliary status")
+
+//auxPrefix introduces an auxiliary channel in a WHERE description: aux:<name>
+const auxPrefix = "aux:"
+
+//AuxStatus is the state of an auxiliary channel: ON, OFF, TOGGLE, STOP, UP, DOWN, ENABLED or DISABLED
+type AuxStatus struct {
+	Where Where
+	State string
+}
+
+//NewAuxStatus decodes an auxiliary event or status answer
+func NewAuxStatus(msg Message) (AuxStatus, error) {
+	if msg.Who == nil || msg.Who.Code != whoAux().Code || msg.Kind != COMMAND || msg.What.Desc == "" {
+		return AuxStatus{}, ErrNotAuxStatus
+	}
+	return AuxStatus{Where: msg.Where, State: msg.What.Desc}, nil
+}
+
+//auxCode returns the WHERE code of the auxiliary channel with the given name
+func (p *Plant) auxCode(name string) (string, error) {
+	n, ok := p.Aux[name]
+	if !ok {
+		return "", ErrAuxNotFound
+	}
+	if n < 1 || n > 9 {
+		return "", errors.Wrapf(ErrInvalidAddress, "auxiliary channel %d is not in 1-9", n)
+	}
+	return strconv.Itoa(n), nil
+}
+
+//auxFromCode decodes the WHERE of an auxiliary frame: 0 for general, 1-9 for the channels
+func (p *Plant) auxFromCode(code string) (Where, error) {
+	if code == "" {
+		return Where{}, nil
+	}
+	code, params := splitParams(code)
+	where := Where{Code: code, Params: params}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return Where{}, errors.Wrapf(ErrWhereNotInPlant, "where: %v", code)
+	}
+	if n == 0 {
+		where.Desc = GENERAL.Desc
+		return where, nil
+	}
+	for ka, a := range p.Aux {
+		if a == n {
+			where.Desc = auxPrefix + ka
+		}
+	}
+	return where, nil
+}
+
+//AuxStatus asks the state of the auxiliary channels at the given where
+func (h *Home) AuxStatus(where Where) ([]AuxStatus, error) {
+	answers, err := h.Ask(NewRequest(whoAux(), What{}, where))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get auxiliary status of %s", where.Desc)
+	}
+	statuses := make([]AuxStatus, 0, len(answers))
+	for _, a := range answers {
+		s, err := NewAuxStatus(a)
+		if err != nil {
+			continue
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+//SetAux changes the state of an auxiliary channel
+func (h *Home) SetAux(where Where, state string) error {
+	what, err := whoAux().WhatFromDesc(state)
+	if err != nil {
+		return errors.Wrapf(err, "unknown auxiliary state %s", state)
+	}
+	return h.Do(NewCommand(whoAux(), what, where))
+}
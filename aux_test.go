@@ -0,0 +1,55 @@
+package gohome_test
+
+import (
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestAuxStatus(t *testing.T) {
+	plant := loadTestPlant(t)
+	statuses := map[string]gohome.AuxStatus{
+		"*9*1*1##": gohome.AuxStatus{State: "ON", Where: gohome.Where{Desc: "aux:flood"}},
+		"*9*7*4##": gohome.AuxStatus{State: "DISABLED", Where: gohome.Where{Desc: "aux:siren"}},
+		"*9*2*0##": gohome.AuxStatus{State: "TOGGLE", Where: gohome.Where{Desc: "GENERAL"}},
+	}
+	for f, exp := range statuses {
+		s, err := gohome.NewAuxStatus(plant.ParseFrame(f))
+		if err != nil {
+			t.Errorf("aux status not decoded from %s: %v", f, err)
+			continue
+		}
+		if s.State != exp.State || s.Where.Desc != exp.Where.Desc {
+			t.Errorf("wrong aux status from %s: %+v", f, s)
+		}
+	}
+	if s, err := gohome.NewAuxStatus(plant.ParseFrame("*1*1*21##")); err == nil {
+		t.Errorf("light message should not be an aux status: %+v", s)
+	}
+	where, err := plant.WhereFromDesc("aux:siren")
+	if err != nil {
+		t.Errorf("Where not found: %v", err)
+	}
+	who := gohome.NewWho("AUX")
+	what, err := who.WhatFromDesc("ENABLED")
+	if err != nil {
+		t.Errorf("What not found: %v", err)
+	}
+	if frame := gohome.NewCommand(who, what, where).Frame(); frame != "*9*6*4##" {
+		t.Errorf("wrong aux frame: %s", frame)
+	}
+}
+
+func TestLoadStatus(t *testing.T) {
+	plant := loadTestPlant(t)
+	s, err := gohome.NewLoadStatus(plant.ParseFrame("*3*2*71#0##"))
+	if err != nil {
+		t.Errorf("load status not decoded: %v", err)
+	}
+	if s.State != "LOAD_FORCED" || s.Where.Desc != "load:oven" {
+		t.Errorf("wrong load status: %+v", s)
+	}
+	if s, err := gohome.NewLoadStatus(plant.ParseFrame("*9*1*1##")); err == nil {
+		t.Errorf("aux message should not be a load status: %+v", s)
+	}
+}
@@ -3,7 +3,7 @@ package gohome
 import (
 	"encoding/json"
 	"fmt"
-	"regexp"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -13,53 +13,67 @@ const REQUEST = "REQUEST"
 const SPECIAL = "SPECIAL"
 const DIMENSIONGET = "DIMENSIONGET"
 const DIMENSIONSET = "DIMENSIONSET"
+const DIMENSIONRESPONSE = "DIMENSIONRESPONSE"
+const ACKNOWLEDGE = "ACKNOWLEDGE"
+const NOTACKNOWLEDGE = "NOTACKNOWLEDGE"
+const SESSION = "SESSION"
 const INVALID = "INVALID"
 
 var ErrWhatNotFound = errors.New("WHAT not found")
 var ErrWhoNotFound = errors.New("WHO not found")
-
-var regexpCommand = regexp.MustCompile(`^\*([0-9]{1,2})\*([0-9]{1,2})\*([0-9]{1,2})##`)
-var regexpRequest = regexp.MustCompile(`^\*#([0-9]{1,2})\*([0-9]{1,2})##`)
-var regexpDimensionGet = regexp.MustCompile(`^\*#([0-9]{1,2})\*([0-9]{1,2})\*([0-9]{1,2})##`)
-var regexpDimensionSet = regexp.MustCompile(`^\*#([0-9]{1,2})\*([0-9]{1,2})\*#([0-9]{1,2})(\*[0-9]{1,2})+##`)
+var ErrDimensionNotFound = errors.New("DIMENSION not found")
 
 type Dimension string
 type Value string
 
 type What struct {
-	Code string
-	Desc string
+	Code   string
+	Desc   string
+	Params []string
+}
+
+//Field returns the WHAT with its parameters as written in a frame
+func (w What) Field() Field {
+	return Field{Code: w.Code, Params: w.Params}
 }
 
 type Message struct {
-	Who     *Who   `json:"who"`
-	What    What   `json:"what"`
-	Where   Where  `json:"where"`
-	Kind    string `json:"kind"`
-	special string
+	Who       *Who      `json:"who"`
+	What      What      `json:"what"`
+	Where     Where     `json:"where"`
+	Dimension Dimension `json:"dimension"`
+	Values    []Value   `json:"values"`
+	Kind      string    `json:"kind"`
+	special   string
 }
 
 func (m Message) MarshalJSON() ([]byte, error) {
 	var whoD, whatD, whereD string
 	if m.Who != nil {
-		whoD = m.Who.Desc
+		whoD = m.Who.LocalDesc()
 	}
-	if m.What != (What{}) {
-		whatD = m.What.Desc
+	if m.What.Desc != "" && m.Who != nil {
+		whatD = withParams(m.Who.LocalWhat(m.What.Desc), m.What.Params)
+	} else if m.What.Desc != "" {
+		whatD = withParams(m.What.Desc, m.What.Params)
 	}
-	if m.Where != (Where{}) {
-		whereD = m.Where.Desc
+	if m.Where.Desc != "" {
+		whereD = withParams(m.Where.Desc, m.Where.Params)
 	}
 	mj := struct {
-		Who   string `json:"who"`
-		What  string `json:"what"`
-		Where string `json:"where"`
-		Kind  string `json:"kind"`
+		Who       string    `json:"who"`
+		What      string    `json:"what"`
+		Where     string    `json:"where"`
+		Dimension Dimension `json:"dimension,omitempty"`
+		Values    []Value   `json:"values,omitempty"`
+		Kind      string    `json:"kind"`
 	}{
-		Who:   whoD,
-		What:  whatD,
-		Where: whereD,
-		Kind:  m.Kind,
+		Who:       whoD,
+		What:      whatD,
+		Where:     whereD,
+		Dimension: m.Dimension,
+		Values:    m.Values,
+		Kind:      m.Kind,
 	}
 	js, err := json.Marshal(&mj)
 	return js, err
@@ -71,15 +85,35 @@ func (m Message) Frame() string {
 	}
 	switch m.Kind {
 	case REQUEST:
-		frame := fmt.Sprintf("*#%s*%s##", m.Who.Code, m.Where.Code)
+		frame := fmt.Sprintf("*#%s*%s##", m.Who.Code, m.Where.Field())
 		return frame
 	case COMMAND:
-		frame := fmt.Sprintf("*%s*%s*%s##", m.Who.Code, m.What.Code, m.Where.Code)
+		frame := fmt.Sprintf("*%s*%s*%s##", m.Who.Code, m.What.Field(), m.Where.Field())
+		return frame
+	case DIMENSIONGET:
+		frame := fmt.Sprintf("*#%s*%s*%s##", m.Who.Code, m.Where.Field(), m.Dimension)
+		return frame
+	case DIMENSIONSET:
+		if len(m.Values) == 0 {
+			return fmt.Sprintf("*#%s*%s*#%s##", m.Who.Code, m.Where.Field(), m.Dimension)
+		}
+		frame := fmt.Sprintf("*#%s*%s*#%s*%s##", m.Who.Code, m.Where.Field(), m.Dimension, m.joinValues())
+		return frame
+	case DIMENSIONRESPONSE:
+		frame := fmt.Sprintf("*#%s*%s*%s*%s##", m.Who.Code, m.Where.Field(), m.Dimension, m.joinValues())
 		return frame
 	}
 	return ""
 }
 
+func (m Message) joinValues() string {
+	vals := make([]string, len(m.Values))
+	for i, v := range m.Values {
+		vals[i] = string(v)
+	}
+	return strings.Join(vals, "*")
+}
+
 func (m Message) IsSpecial() bool {
 	if m.special != "" {
 		return true
@@ -94,6 +128,27 @@ func (m Message) IsValid() bool {
 	return false
 }
 
+//withParams appends the #-separated parameters to a WHAT or WHERE description (eg. SET_50#3)
+func withParams(desc string, params []string) string {
+	if len(params) == 0 {
+		return desc
+	}
+	return desc + "#" + strings.Join(params, "#")
+}
+
+//splitParams separates a WHAT or WHERE from its #-separated parameters, a leading # (group) is kept
+func splitParams(text string) (string, []string) {
+	lead := ""
+	if strings.HasPrefix(text, "#") {
+		lead, text = "#", text[1:]
+	}
+	split := strings.Split(text, "#")
+	if len(split) == 1 {
+		return lead + text, nil
+	}
+	return lead + split[0], split[1:]
+}
+
 //NewCommand build a new Command to send to the home plant
 func NewCommand(who *Who, what What, where Where) Message {
 	return Message{Who: who, What: what, Where: where, Kind: COMMAND}
@@ -104,6 +159,18 @@ func NewRequest(who *Who, what What, where Where) Message {
 	return Message{Who: who, What: what, Where: where, Kind: REQUEST}
 }
 
+//NewDimensionRequest build a new request to read a dimension of a device
+func NewDimensionRequest(who *Who, where Where, dimension Dimension) Message {
+	return Message{Who: who, Where: where, Dimension: dimension, Kind: DIMENSIONGET}
+}
+
+//NewDimensionWrite build a new command to write the values of a dimension of a device
+func NewDimensionWrite(who *Who, where Where, dimension Dimension, values ...Value) Message {
+	return Message{Who: who, Where: where, Dimension: dimension, Values: values, Kind: DIMENSIONSET}
+}
+
+//IsValid checks the OWN syntax of the frame and returns its kind.
+//ACK, NACK and session frames are all reported as SPECIAL.
 func IsValid(msg string) (bool, string) {
 	if len(msg) < 5 {
 		return false, INVALID
@@ -113,15 +180,13 @@ func IsValid(msg string) (bool, string) {
 			return true, SPECIAL
 		}
 	}
-	switch {
-	case regexpCommand.MatchString(msg):
-		return true, COMMAND
-	case regexpRequest.MatchString(msg):
-		return true, REQUEST
-	case regexpDimensionGet.MatchString(msg):
-		return true, DIMENSIONGET
-	case regexpDimensionSet.MatchString(msg):
-		return true, DIMENSIONSET
-	}
-	return false, INVALID
+	frame, err := NewFrame(msg)
+	if err != nil {
+		return false, INVALID
+	}
+	switch frame.Kind {
+	case ACKNOWLEDGE, NOTACKNOWLEDGE, SESSION:
+		return true, SPECIAL
+	}
+	return true, frame.Kind
 }
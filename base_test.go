@@ -2,6 +2,7 @@ package gohome_test
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/savardiego/gohome"
@@ -93,3 +94,43 @@ func TestMessageIsRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestNewDimensionMessages(t *testing.T) {
+	plant := makeTestPlant(t)
+	who := gohome.NewWho("LIGHT")
+	where, err := plant.WhereFromDesc("kitchen.main")
+	if err != nil {
+		t.Errorf("Where not found: %v", err)
+	}
+	messages := map[string]gohome.Message{
+		"*#1*12*1##":        gohome.NewDimensionRequest(who, where, "1"),
+		"*#1*12*#1*150*0##": gohome.NewDimensionWrite(who, where, "1", "150", "0"),
+	}
+	for exp, m := range messages {
+		if m.Frame() != exp {
+			t.Errorf("Wrong dimension frame %s, expected was %s", m.Frame(), exp)
+		}
+	}
+}
+
+func TestParseDimensionFrame(t *testing.T) {
+	plant := makeTestPlant(t)
+	frames := map[string][]string{
+		"*#1*12*1##":        []string{gohome.DIMENSIONGET, "1", ""},
+		"*#1*12*#1*150*0##": []string{gohome.DIMENSIONSET, "1", "150,0"},
+		"*#1*12*1*150*0##":  []string{gohome.DIMENSIONRESPONSE, "1", "150,0"},
+	}
+	for f, e := range frames {
+		msg := plant.ParseFrame(f)
+		vals := make([]string, len(msg.Values))
+		for i, v := range msg.Values {
+			vals[i] = string(v)
+		}
+		if msg.Kind != e[0] || string(msg.Dimension) != e[1] || strings.Join(vals, ",") != e[2] {
+			t.Errorf("Wrong dimension decoded for '%s': %s %s %v", f, msg.Kind, msg.Dimension, msg.Values)
+		}
+		if msg.Frame() != f {
+			t.Errorf("Wrong frame rendered %s, expected was %s", msg.Frame(), f)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package gohome
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+//ErrKeypadNotFound is returned when the desired keypad is not found in the conf file
+var ErrKeypadNotFound = errors.New("keypad not found")
+
+//ErrNotButtonEvent is returned when a message is not a CEN or CEN+ button event
+var ErrNotButtonEvent = errors.New("message is not a button event")
+
+//keypadPrefix introduces a CEN/CEN+ keypad in a WHERE description: keypad:<name>
+const keypadPrefix = "keypad:"
+
+//Kinds of push of a scenario button, named as the WHATs of the CEN+ WHO. PUSH_START is sent only by CEN keypads,
+//PUSH_START_EXTENDED only by CEN+ ones.
+const PUSH_START = "START_PRESS"
+const PUSH_START_EXTENDED = "START_EXTENDED_PRESS"
+const PUSH_SHORT = "SHORT_PRESS"
+const PUSH_EXTENDED = "EXTENDED_PRESS"
+const PUSH_RELEASE = "EXTENDED_RELEASE"
+
+//cenPushes maps the WHAT parameter of a CEN frame to the kind of push
+var cenPushes = map[string]string{
+	"":  PUSH_START,
+	"1": PUSH_SHORT,
+	"2": PUSH_EXTENDED,
+	"3": PUSH_RELEASE,
+}
+
+//cenPlusPushes maps the WHAT of a CEN+ frame to the kind of push
+var cenPlusPushes = map[string]string{
+	"21": PUSH_SHORT,
+	"22": PUSH_START_EXTENDED,
+	"23": PUSH_EXTENDED,
+	"24": PUSH_RELEASE,
+}
+
+//ButtonEvent is the push of a button of a CEN (WHO 15) or CEN+ (WHO 25) keypad
+type ButtonEvent struct {
+	Where   Where
+	Button  int
+	Push    string
+	CENPlus bool
+}
+
+//NewButtonEvent decodes a CEN or CEN+ message
+func NewButtonEvent(msg Message) (ButtonEvent, error) {
+	if msg.Who == nil || msg.Kind != COMMAND {
+		return ButtonEvent{}, ErrNotButtonEvent
+	}
+	switch msg.Who.Code {
+	case whoCEN().Code:
+		button, err := strconv.Atoi(msg.What.Code)
+		if err != nil {
+			return ButtonEvent{}, errors.Wrapf(ErrNotButtonEvent, "wrong button %s", msg.What.Code)
+		}
+		par := ""
+		if len(msg.What.Params) > 0 {
+			par = msg.What.Params[0]
+		}
+		push, ok := cenPushes[par]
+		if !ok {
+			return ButtonEvent{}, errors.Wrapf(ErrNotButtonEvent, "unknown push %s", par)
+		}
+		return ButtonEvent{Where: msg.Where, Button: button, Push: push}, nil
+	case whoCENPlus().Code:
+		push, ok := cenPlusPushes[msg.What.Code]
+		if !ok || len(msg.What.Params) == 0 {
+			return ButtonEvent{}, errors.Wrapf(ErrNotButtonEvent, "unknown push %s", msg.What.Code)
+		}
+		button, err := strconv.Atoi(msg.What.Params[0])
+		if err != nil {
+			return ButtonEvent{}, errors.Wrapf(ErrNotButtonEvent, "wrong button %s", msg.What.Params[0])
+		}
+		return ButtonEvent{Where: msg.Where, Button: button, Push: push, CENPlus: true}, nil
+	}
+	return ButtonEvent{}, ErrNotButtonEvent
+}
+
+//Command returns the message that emulates the button push on the bus
+func (e ButtonEvent) Command() (Message, error) {
+	if e.Button < 0 || e.Button > 31 {
+		return Message{}, errors.Errorf("button %d is not in 0-31", e.Button)
+	}
+	if e.CENPlus {
+		for code, push := range cenPlusPushes {
+			if push == e.Push {
+				what := What{Code: code, Desc: whoCENPlus().Actions[code], Params: []string{strconv.Itoa(e.Button)}}
+				return NewCommand(whoCENPlus(), what, e.Where), nil
+			}
+		}
+		return Message{}, errors.Errorf("unknown push %s", e.Push)
+	}
+	for par, push := range cenPushes {
+		if push == e.Push {
+			code := fmt.Sprintf("%02d", e.Button)
+			what := What{Code: code, Desc: whoCEN().Actions[code]}
+			if par != "" {
+				what.Params = []string{par}
+			}
+			return NewCommand(whoCEN(), what, e.Where), nil
+		}
+	}
+	return Message{}, errors.Errorf("unknown push %s", e.Push)
+}
+
+//String returns a human readable description of the event
+func (e ButtonEvent) String() string {
+	return fmt.Sprintf("button %d %s on '%s'", e.Button, e.Push, e.Where.Desc)
+}
+
+//keypadCode returns the WHERE code of the keypad with the given name
+func (p *Plant) keypadCode(name string) (string, error) {
+	code, ok := p.Keypads[name]
+	if !ok {
+		return "", ErrKeypadNotFound
+	}
+	return code, nil
+}
+
+//keypadFromCode decodes the WHERE of a CEN/CEN+ frame with the keypads of the plant
+func (p *Plant) keypadFromCode(code string) (Where, error) {
+	code, params := splitParams(code)
+	where := Where{Code: code, Params: params}
+	for kk, k := range p.Keypads {
+		if k == code {
+			where.Desc = keypadPrefix + kk
+		}
+	}
+	return where, nil
+}
+
+//Press emulates the push of a button, as CEN+ virtual keypads do
+func (h *Home) Press(e ButtonEvent) error {
+	cmd, err := e.Command()
+	if err != nil {
+		return err
+	}
+	return h.Do(cmd)
+}
@@ -0,0 +1,62 @@
+package gohome_test
+
+import (
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestButtonEvent(t *testing.T) {
+	plant := loadTestPlant(t)
+	events := map[string]gohome.ButtonEvent{
+		"*15*05*21##":     gohome.ButtonEvent{Button: 5, Push: gohome.PUSH_START},
+		"*15*05#1*21##":   gohome.ButtonEvent{Button: 5, Push: gohome.PUSH_SHORT},
+		"*15*31#2*21##":   gohome.ButtonEvent{Button: 31, Push: gohome.PUSH_EXTENDED},
+		"*15*00#3*21##":   gohome.ButtonEvent{Button: 0, Push: gohome.PUSH_RELEASE},
+		"*25*21#5*212##":  gohome.ButtonEvent{Button: 5, Push: gohome.PUSH_SHORT, CENPlus: true},
+		"*25*22#12*212##": gohome.ButtonEvent{Button: 12, Push: gohome.PUSH_START_EXTENDED, CENPlus: true},
+		"*25*24#12*212##": gohome.ButtonEvent{Button: 12, Push: gohome.PUSH_RELEASE, CENPlus: true},
+	}
+	for f, exp := range events {
+		msg := plant.ParseFrame(f)
+		e, err := gohome.NewButtonEvent(msg)
+		if err != nil {
+			t.Errorf("button event not decoded from %s: %v", f, err)
+			continue
+		}
+		if e.Button != exp.Button || e.Push != exp.Push || e.CENPlus != exp.CENPlus {
+			t.Errorf("wrong button event from %s: %+v", f, e)
+		}
+		if (e.CENPlus && e.Where.Desc != "keypad:virtual") || (!e.CENPlus && e.Where.Desc != "keypad:hall") {
+			t.Errorf("wrong keypad from %s: %s", f, e.Where.Desc)
+		}
+		cmd, err := e.Command()
+		if err != nil || cmd.Frame() != f {
+			t.Errorf("wrong command from button event %+v: %s (err: %v)", e, cmd.Frame(), err)
+		}
+		if e.CENPlus && cmd.What.Desc != e.Push {
+			t.Errorf("push %s named %s by the CEN+ WHO", e.Push, cmd.What.Desc)
+		}
+	}
+	for _, f := range []string{"*15*05#7*21##", "*25*21*212##", "*25*30#1*212##", "*1*1*21##"} {
+		if e, err := gohome.NewButtonEvent(plant.ParseFrame(f)); err == nil {
+			t.Errorf("frame %s should not be a button event: %+v", f, e)
+		}
+	}
+}
+
+func TestVirtualButtonPress(t *testing.T) {
+	plant := loadTestPlant(t)
+	who := gohome.NewWho("CEN_PLUS")
+	what, err := who.WhatFromDesc("SHORT_PRESS#3")
+	if err != nil {
+		t.Errorf("What not found: %v", err)
+	}
+	where, err := plant.WhereFromDesc("keypad:virtual")
+	if err != nil {
+		t.Errorf("Where not found: %v", err)
+	}
+	if frame := gohome.NewCommand(who, what, where).Frame(); frame != "*25*21#3*212##" {
+		t.Errorf("Wrong CEN+ command %s", frame)
+	}
+}
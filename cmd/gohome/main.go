@@ -2,25 +2,48 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/savardiego/gohome"
 )
 
 const defaultConf = "gohome.json"
+const defaultWhoConf = "who.json"
+const defaultLocaleConf = "locale.json"
 const defaultSysConf = ".gohome/gohome.json"
 
+//reloadInterval is how often listen, listenT and remote check if the configuration file changed
+const reloadInterval = 5 * time.Second
+
 func main() {
 	//command line must be WHO WHAT WHERE
 	if len(os.Args) < 2 {
 		basicHelp()
 		return
 	}
+	if err := setLocale(); err != nil {
+		fmt.Printf("Cannot set the locale: %v\n", err)
+	}
+	for i := 1; i < len(os.Args)-1; i++ {
+		if os.Args[i] == "--plant" {
+			plantName = os.Args[i+1]
+			os.Args = append(os.Args[:i], os.Args[i+2:]...)
+			break
+		}
+	}
+	if len(os.Args) < 2 {
+		basicHelp()
+		return
+	}
 	var err error
 	cmd := os.Args[1]
 	switch cmd {
@@ -39,6 +62,36 @@ func main() {
 			fmt.Printf("Cannot complete command executiion: %+v\n", err)
 		}
 		break
+	case "zone":
+		err = zoneCommand(os.Args[2:])
+		break
+	case "alarm":
+		err = alarmCommand(os.Args[2:])
+		break
+	case "gateway":
+		err = gatewayCommand(os.Args[2:])
+		break
+	case "energy":
+		err = energyCommand(os.Args[2:])
+		break
+	case "audio":
+		err = audioCommand(os.Args[2:])
+		break
+	case "door":
+		err = doorCommand(os.Args[2:])
+		break
+	case "scenario":
+		err = scenarioCommand(os.Args[2:])
+		break
+	case "diag":
+		err = diagCommand(os.Args[2:])
+		break
+	case "config":
+		err = configCommand(os.Args[2:])
+		break
+	case "discover":
+		err = discoverCommand(os.Args[2:])
+		break
 	case "listen":
 		err = listen()
 		break
@@ -58,14 +111,14 @@ func main() {
 }
 
 func executeCommand(command []string) error {
-	home, err := openHome()
+	homes, _, err := openHomes()
 	if err != nil {
 		return errors.Wrapf(err, "cannot open Home")
 	}
 	fmt.Printf("who is %s\n", command[0])
-	who := gohome.NewWho(command[0])
-	if who.Desc == "" {
-		return errors.Errorf("unknown <who> in command:%s", command[0])
+	who, err := gohome.LookupWho(command[0])
+	if err != nil {
+		return errors.Wrapf(err, "unknown <who> in command:%s", command[0])
 	}
 	fmt.Printf("what is %s\n", command[1])
 	what, err := who.WhatFromDesc(command[1])
@@ -73,7 +126,11 @@ func executeCommand(command []string) error {
 		return errors.Errorf("Cannot get <what> from command: %s due to: %v", command[1], err)
 	}
 	fmt.Printf("where is %s\n", command[2])
-	where, err := home.Plant.WhereFromDesc(command[2])
+	target := command[2]
+	if plantName != "" {
+		target = plantName + "@" + target
+	}
+	home, where, err := homes.Target(target)
 	if err != nil {
 		return errors.Wrapf(err, "Cannot get <where> from command: %s due to: %v", command[2], err)
 	}
@@ -88,12 +145,74 @@ func showPlant(command []string) error {
 		return errors.Wrapf(err, "cannot open Home")
 	}
 	fmt.Println("-------------------")
-	fmt.Printf("Plant: %s\n\n", home.Plant.Name)
+	fmt.Printf("Plant: %s\n\n", home.Current().Name)
+	fmt.Printf("Floors:\n")
+	for _, f := range home.Current().FloorNames() {
+		floor := home.Current().Floors[f]
+		fmt.Printf("     %s: %d %v\n", f, floor.Level, floor.Ambients)
+	}
 	fmt.Printf("Ambients:\n")
-	for a, amb := range home.Plant.Ambients {
+	for a, amb := range home.Current().Ambients {
 		fmt.Printf("     %s: %d\n", a, amb.Num)
-		for l, n := range amb.Lights {
-			fmt.Printf("          %s: %d\n", l, n)
+		for d, dev := range amb.Devices {
+			fmt.Printf("          %s: %s (%s, WHO %s)\n", d, dev.Address, dev.Type, dev.Who)
+		}
+	}
+	fmt.Printf("Groups:\n")
+	for g, grp := range home.Current().Groups {
+		fmt.Printf("     %s: #%d %v\n", g, grp.Num, grp.Members)
+	}
+	fmt.Printf("Zones:\n")
+	for z, zone := range home.Current().Zones {
+		fmt.Printf("     %s: %d %v\n", z, zone.Num, zone.Ambients)
+	}
+	fmt.Printf("Alarm zones:\n")
+	for a, n := range home.Current().Alarms {
+		fmt.Printf("     %s: %d\n", a, n)
+	}
+	fmt.Printf("Keypads:\n")
+	for k, c := range home.Current().Keypads {
+		fmt.Printf("     %s: %s\n", k, c)
+	}
+	fmt.Printf("Meters:\n")
+	for m, n := range home.Current().Meters {
+		fmt.Printf("     %s: %d\n", m, n)
+	}
+	fmt.Printf("Loads:\n")
+	for l, n := range home.Current().Loads {
+		fmt.Printf("     %s: %d\n", l, n)
+	}
+	fmt.Printf("Auxiliary channels:\n")
+	for a, n := range home.Current().Aux {
+		fmt.Printf("     %s: %d\n", a, n)
+	}
+	fmt.Printf("Entrance panels:\n")
+	for e, n := range home.Current().Panels {
+		fmt.Printf("     %s: %d\n", e, n)
+	}
+	fmt.Printf("Door locks:\n")
+	for l, n := range home.Current().Locks {
+		fmt.Printf("     %s: %d\n", l, n)
+	}
+	fmt.Printf("Scenario modules:\n")
+	for m, n := range home.Current().Modules {
+		fmt.Printf("     %s: %d\n", m, n)
+	}
+	fmt.Printf("Scenarios:\n")
+	for sc, s := range home.Current().Scenarios {
+		fmt.Printf("     %s: %d %s\n", sc, s.Num, s.Module)
+	}
+	if home.Current().Audio != nil {
+		fmt.Printf("Sound zones:\n")
+		for z, zone := range home.Current().Audio.Zones {
+			fmt.Printf("     %s: %d\n", z, zone.Area)
+			for sp, n := range zone.Speakers {
+				fmt.Printf("          %s: %d\n", sp, n)
+			}
+		}
+		fmt.Printf("Sound sources:\n")
+		for so, n := range home.Current().Audio.Sources {
+			fmt.Printf("     %s: %d\n", so, n)
 		}
 	}
 	return nil
@@ -109,6 +228,32 @@ func showHome(command []string) error {
 	if err != nil {
 		return errors.Wrapf(err, "cannot get plant status, queryFrame: %s", queryStatus.Kind)
 	}
+	if len(home.Current().Aux) > 0 {
+		whoAux, err := gohome.LookupWho("AUX")
+		if err != nil {
+			return err
+		}
+		aux, err := home.Ask(gohome.NewRequest(whoAux, gohome.What{}, gohome.GENERAL))
+		if err != nil {
+			return errors.Wrapf(err, "cannot get auxiliary status")
+		}
+		statuses = append(statuses, aux...)
+	}
+	whoLoad, err := gohome.LookupWho("LOAD_CONTROL")
+	if err != nil {
+		return err
+	}
+	for l := range home.Current().Loads {
+		load, err := home.Current().WhereFromDesc("load:" + l)
+		if err != nil {
+			return err
+		}
+		answers, err := home.Ask(gohome.NewRequest(whoLoad, gohome.What{}, load))
+		if err != nil {
+			return errors.Wrapf(err, "cannot get status of load %s", l)
+		}
+		statuses = append(statuses, answers...)
+	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
 	fmt.Fprintln(w, "N\tWHO\tWHERE\tSTATUS")
 	for i, m := range statuses {
@@ -121,11 +266,432 @@ func showHome(command []string) error {
 	return nil
 }
 
-func listen() error {
+func zoneCommand(command []string) error {
+	home, err := openHome()
+	if err != nil {
+		return errors.Wrapf(err, "cannot open Home")
+	}
+	if len(command) == 0 || command[0] == "show" {
+		names := command
+		if len(names) > 0 {
+			names = names[1:]
+		}
+		if len(names) == 0 {
+			for z := range home.Current().Zones {
+				names = append(names, z)
+			}
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+		fmt.Fprintln(w, "ZONE\tMODE\tTEMPERATURE\tSETPOINT\tOFFSET\tVALVES")
+		for _, n := range names {
+			where, err := home.Current().WhereFromDesc("zone:" + n)
+			if err != nil {
+				return errors.Wrapf(err, "unknown zone %s", n)
+			}
+			status, err := home.ZoneStatus(where)
+			if err != nil {
+				return errors.Wrapf(err, "cannot read zone %s", n)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%.1f\t%.1f\t%s\t%v\n", n, status.Mode, status.Temperature, status.Setpoint, status.Offset, status.Valves)
+		}
+		w.Flush()
+		return nil
+	}
+	if len(command) < 3 {
+		return errors.Errorf("missing arguments, usage: zone set <zone> <temperature> [heating|cooling|generic] or zone mode <zone> <mode>")
+	}
+	where, err := home.Current().WhereFromDesc("zone:" + command[1])
+	if err != nil {
+		return errors.Wrapf(err, "unknown zone %s", command[1])
+	}
+	switch command[0] {
+	case "set":
+		temp, err := strconv.ParseFloat(command[2], 64)
+		if err != nil {
+			return errors.Wrapf(err, "wrong temperature %s", command[2])
+		}
+		function := gohome.SETPOINT_GENERIC
+		if len(command) > 3 {
+			switch strings.ToLower(command[3]) {
+			case "heating":
+				function = gohome.SETPOINT_HEATING
+			case "cooling":
+				function = gohome.SETPOINT_COOLING
+			}
+		}
+		return home.SetZoneSetpoint(where, temp, function)
+	case "mode":
+		return home.SetZoneMode(where, command[2])
+	}
+	return errors.Errorf("unknown zone command: %s", command[0])
+}
+
+func alarmCommand(command []string) error {
+	if len(command) == 0 || command[0] != "status" {
+		return errors.Errorf("unknown alarm command, usage: alarm status")
+	}
+	home, err := openHome()
+	if err != nil {
+		return errors.Wrapf(err, "cannot open Home")
+	}
+	status, err := home.AlarmStatus()
+	if err != nil {
+		return errors.Wrapf(err, "cannot get alarm status")
+	}
+	fmt.Printf("Engaged: %t\n", status.Engaged)
+	fmt.Printf("Maintenance: %t\n", status.Maintenance)
+	fmt.Printf("Battery: %s\n", status.Battery)
+	fmt.Printf("Network: %s\n", status.Network)
+	w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "ZONE\tSTATE")
+	zones := map[string]string{}
+	for name, num := range home.Current().Alarms {
+		zones[strconv.Itoa(num)] = name
+	}
+	for z, st := range status.Zones {
+		if name, ok := zones[z]; ok {
+			z = fmt.Sprintf("%s (%s)", z, name)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", z, st)
+	}
+	w.Flush()
+	for _, a := range status.Alarms {
+		fmt.Printf("%s\n", a)
+	}
+	return nil
+}
+
+func gatewayCommand(command []string) error {
+	if len(command) == 0 {
+		return errors.Errorf("missing gateway command, usage: gateway info|sync-time")
+	}
+	home, err := openHome()
+	if err != nil {
+		return errors.Wrapf(err, "cannot open Home")
+	}
+	switch command[0] {
+	case "info":
+		info, err := home.GatewayInfo()
+		if err != nil {
+			return errors.Wrapf(err, "cannot read gateway information")
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+		fmt.Fprintf(w, "Model:\t%s\n", info.Model)
+		fmt.Fprintf(w, "Firmware:\t%s\n", info.Firmware)
+		fmt.Fprintf(w, "Kernel:\t%s\n", info.Kernel)
+		fmt.Fprintf(w, "Distribution:\t%s\n", info.Distribution)
+		fmt.Fprintf(w, "IP:\t%s\n", info.IP)
+		fmt.Fprintf(w, "Netmask:\t%s\n", info.Netmask)
+		fmt.Fprintf(w, "MAC:\t%s\n", info.MAC)
+		fmt.Fprintf(w, "Time:\t%s\n", info.Time.Format(time.RFC1123Z))
+		fmt.Fprintf(w, "Drift:\t%s\n", info.Time.Sub(time.Now()).Round(time.Second))
+		fmt.Fprintf(w, "Uptime:\t%s\n", info.Uptime)
+		w.Flush()
+		return nil
+	case "sync-time":
+		now := time.Now()
+		if err := home.SetGatewayTime(now); err != nil {
+			return errors.Wrapf(err, "cannot set gateway time")
+		}
+		fmt.Printf("Gateway time set to %s\n", now.Format(time.RFC1123Z))
+		return nil
+	}
+	return errors.Errorf("unknown gateway command: %s", command[0])
+}
+
+func energyCommand(command []string) error {
+	home, err := openHome()
+	if err != nil {
+		return errors.Wrapf(err, "cannot open Home")
+	}
+	if len(command) > 1 && command[0] == "updates" {
+		minutes, err := strconv.Atoi(command[1])
+		if err != nil {
+			return errors.Wrapf(err, "wrong minutes %s", command[1])
+		}
+		for m := range home.Current().Meters {
+			meter, err := home.Current().WhereFromDesc("meter:" + m)
+			if err != nil {
+				return err
+			}
+			if minutes == 0 {
+				err = home.StopPowerUpdates(meter)
+			} else {
+				err = home.StartPowerUpdates(meter, minutes)
+			}
+			if err != nil {
+				return errors.Wrapf(err, "cannot change power updates of meter %s", m)
+			}
+		}
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "METER\tPOWER (W)\tTODAY (kWh)\tMONTH (kWh)\tTOTAL (kWh)")
+	for m := range home.Current().Meters {
+		meter, err := home.Current().WhereFromDesc("meter:" + m)
+		if err != nil {
+			return err
+		}
+		power, err := home.ActivePower(meter)
+		if err != nil {
+			return errors.Wrapf(err, "cannot read power of meter %s", m)
+		}
+		today, err := home.EnergyToday(meter)
+		if err != nil {
+			return errors.Wrapf(err, "cannot read energy of meter %s", m)
+		}
+		month, err := home.EnergyThisMonth(meter)
+		if err != nil {
+			return errors.Wrapf(err, "cannot read energy of meter %s", m)
+		}
+		total, err := home.EnergyTotal(meter)
+		if err != nil {
+			return errors.Wrapf(err, "cannot read energy of meter %s", m)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%.3f\t%.3f\t%.3f\n", m, power, float64(today)/1000, float64(month)/1000, float64(total)/1000)
+	}
+	w.Flush()
+	return nil
+}
+
+func audioCommand(command []string) error {
+	home, err := openHome()
+	if err != nil {
+		return errors.Wrapf(err, "cannot open Home")
+	}
+	if home.Current().Audio == nil {
+		return errors.Errorf("no sound system in the plant")
+	}
+	if len(command) == 0 || command[0] == "show" {
+		w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+		fmt.Fprintln(w, "ZONE\tON\tVOLUME\tSOURCE")
+		for z := range home.Current().Audio.Zones {
+			zone, err := home.Current().WhereFromDesc("audio:" + z)
+			if err != nil {
+				return err
+			}
+			status, err := home.AudioStatus(zone)
+			if err != nil {
+				return errors.Wrapf(err, "cannot read sound zone %s", z)
+			}
+			fmt.Fprintf(w, "%s\t%t\t%d\t%d\n", z, status.On, status.Volume, status.Source)
+		}
+		w.Flush()
+		return nil
+	}
+	if len(command) < 2 {
+		return errors.Errorf("missing arguments, usage: audio on|off <zone>, audio source <zone> <source>, audio volume <zone> <0-31|+N|-N>")
+	}
+	zone, err := home.Current().WhereFromDesc("audio:" + command[1])
+	if err != nil {
+		return errors.Wrapf(err, "unknown sound zone %s", command[1])
+	}
+	switch command[0] {
+	case "on":
+		return home.SetAudio(zone, true)
+	case "off":
+		return home.SetAudio(zone, false)
+	case "source":
+		if len(command) < 3 {
+			return errors.Errorf("missing source, usage: audio source <zone> <source>")
+		}
+		source, err := home.Current().WhereFromDesc("source:" + command[2])
+		if err != nil {
+			return errors.Wrapf(err, "unknown sound source %s", command[2])
+		}
+		return home.SetSource(zone, source)
+	case "volume":
+		if len(command) < 3 {
+			return errors.Errorf("missing volume, usage: audio volume <zone> <0-31|+N|-N>")
+		}
+		volume, err := strconv.Atoi(command[2])
+		if err != nil {
+			return errors.Wrapf(err, "wrong volume %s", command[2])
+		}
+		if strings.HasPrefix(command[2], "+") || strings.HasPrefix(command[2], "-") {
+			return home.StepVolume(zone, volume)
+		}
+		return home.SetVolume(zone, volume)
+	}
+	return errors.Errorf("unknown audio command: %s", command[0])
+}
+
+func doorCommand(command []string) error {
+	if len(command) < 2 {
+		return errors.Errorf("missing arguments, usage: door open <lock> or door light <panel>")
+	}
+	home, err := openHome()
+	if err != nil {
+		return errors.Wrapf(err, "cannot open Home")
+	}
+	switch command[0] {
+	case "open":
+		lock, err := home.Current().WhereFromDesc("lock:" + command[1])
+		if err != nil {
+			return errors.Wrapf(err, "unknown door lock %s", command[1])
+		}
+		return home.OpenLock(lock)
+	case "light":
+		panel, err := home.Current().WhereFromDesc("panel:" + command[1])
+		if err != nil {
+			return errors.Wrapf(err, "unknown entrance panel %s", command[1])
+		}
+		return home.StairLight(panel)
+	}
+	return errors.Errorf("unknown door command: %s", command[0])
+}
+
+func scenarioCommand(command []string) error {
 	home, err := openHome()
 	if err != nil {
 		return errors.Wrapf(err, "cannot open Home")
 	}
+	if len(command) == 0 || command[0] == "list" {
+		w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+		fmt.Fprintln(w, "SCENARIO\tNUM\tMODULE")
+		for sc, s := range home.Current().Scenarios {
+			module := s.Module
+			if module == "" {
+				module = "MH200N"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\n", sc, s.Num, module)
+		}
+		w.Flush()
+		return nil
+	}
+	if len(command) < 2 {
+		return errors.Errorf("missing scenario, usage: scenario run|stop|enable|disable|record|end-record|erase <name>")
+	}
+	name := command[1]
+	switch command[0] {
+	case "run":
+		return home.RunScenario(name)
+	case "stop":
+		return home.StopScenario(name)
+	case "enable":
+		return home.EnableScenario(name, true)
+	case "disable":
+		return home.EnableScenario(name, false)
+	case "record":
+		return home.RecordScenario(name, true)
+	case "end-record":
+		return home.RecordScenario(name, false)
+	case "erase":
+		return home.EraseScenario(name)
+	}
+	return errors.Errorf("unknown scenario command: %s", command[0])
+}
+
+func configCommand(command []string) error {
+	if len(command) == 0 || command[0] != "validate" {
+		return errors.Errorf("usage: config validate [<file>]")
+	}
+	var config *os.File
+	var err error
+	if len(command) > 1 {
+		config, err = os.Open(command[1])
+	} else if config, err = openSysPlantFile(); err != nil {
+		config, err = openPlantFile()
+	}
+	if err != nil {
+		return errors.Wrapf(err, "cannot open configuration file")
+	}
+	defer config.Close()
+	if err := loadWhoFile(filepath.Join(filepath.Dir(config.Name()), defaultWhoConf)); err != nil {
+		return err
+	}
+	_, err = gohome.NewStrictHomes(config)
+	problems, ok := errors.Cause(err).(gohome.Problems)
+	if err != nil && !ok {
+		return errors.Wrapf(err, "cannot load plant from configuration file: %s", config.Name())
+	}
+	for _, pr := range problems {
+		fmt.Printf("%s: %v\n", pr.Path, pr.Err)
+	}
+	if len(problems) > 0 {
+		return errors.Errorf("%s has %d problems", config.Name(), len(problems))
+	}
+	fmt.Printf("%s is valid\n", config.Name())
+	return nil
+}
+
+func discoverCommand(command []string) error {
+	merge := len(command) > 0 && command[0] == "merge"
+	if merge {
+		command = command[1:]
+	}
+	format := gohome.FORMAT_JSON
+	if n := len(command); n > 0 {
+		switch command[n-1] {
+		case gohome.FORMAT_JSON, gohome.FORMAT_YAML, gohome.FORMAT_TOML:
+			format, command = command[n-1], command[:n-1]
+		}
+	}
+	var home *gohome.Home
+	var err error
+	if len(command) > 2 || (merge && len(command) > 0) {
+		return errors.Errorf("usage: discover [<host>:<port> [<password>]] [json|yaml|toml] or discover merge [json|yaml|toml]")
+	}
+	if len(command) > 0 {
+		plant := &gohome.Plant{Address: command[0]}
+		if len(command) > 1 {
+			plant.Password = command[1]
+		}
+		home = gohome.NewHome(plant)
+	} else if home, err = openHome(); err != nil {
+		return errors.Wrapf(err, "cannot open Home")
+	}
+	plant, err := home.Discover(merge)
+	if err != nil {
+		return errors.Wrapf(err, "cannot discover the plant")
+	}
+	return plant.ExportPlantAs(os.Stdout, format)
+}
+
+func diagCommand(command []string) error {
+	if len(command) == 0 {
+		return errors.Errorf("missing where, usage: diag <where>|zone:<zone>|gateway")
+	}
+	home, err := openHome()
+	if err != nil {
+		return errors.Wrapf(err, "cannot open Home")
+	}
+	name := "AUTOMATION"
+	where := gohome.Where{}
+	switch {
+	case command[0] == "gateway":
+		name = "GATEWAY"
+	case strings.HasPrefix(command[0], "zone:"):
+		name = "THERMOREGULATION"
+		fallthrough
+	default:
+		if where, err = home.Current().WhereFromDesc(command[0]); err != nil {
+			return errors.Wrapf(err, "unknown where %s", command[0])
+		}
+	}
+	who, err := gohome.LookupWho(name)
+	if err != nil {
+		return err
+	}
+	reports, err := home.Diagnose(who, where)
+	if err != nil {
+		return errors.Wrapf(err, "cannot diagnose %s", command[0])
+	}
+	if len(reports) == 0 {
+		fmt.Printf("No diagnostic received from %s\n", command[0])
+	}
+	for _, r := range reports {
+		fmt.Printf("%s\n", r)
+	}
+	return nil
+}
+
+func listen() error {
+	home, file, err := openHomeFile()
+	if err != nil {
+		return errors.Wrapf(err, "cannot open Home")
+	}
+	defer close(watchPlant(home, file))
 	listen, _, errs := home.Listen()
 	ok := true
 	for ok {
@@ -134,8 +700,29 @@ func listen() error {
 			fmt.Printf(">>>>> error received (ok? %t): %v\n", ok, e)
 		case f, ok := <-listen:
 			if v, _ := gohome.IsValid(f); v {
-				msg := home.Plant.ParseFrame(f)
+				msg := home.Current().ParseFrame(f)
 				fmt.Printf(">>>>> received (ok? %t): '%s' '%s' '%s'  msg: '%v'\n", ok, msg.Who.Desc, msg.What.Desc, msg.Where.Desc, msg.Kind)
+				if e, err := gohome.NewAlarmEvent(msg); err == nil {
+					fmt.Printf(">>>>> alarm event: %s\n", e)
+				}
+				if e, err := gohome.NewButtonEvent(msg); err == nil {
+					fmt.Printf(">>>>> button event: %s\n", e)
+				}
+				if r, err := gohome.NewEnergyReading(msg); err == nil {
+					fmt.Printf(">>>>> energy: %s\n", r)
+				}
+				if e, err := gohome.NewDoorEvent(msg); err == nil {
+					fmt.Printf(">>>>> door event: %s\n", e)
+				}
+				if e, err := gohome.NewScenarioEvent(msg); err == nil {
+					fmt.Printf(">>>>> scenario event: %s\n", e)
+				}
+				if s, err := gohome.NewAuxStatus(msg); err == nil {
+					fmt.Printf(">>>>> aux: %s %s\n", s.Where.Desc, s.State)
+				}
+				if s, err := gohome.NewLoadStatus(msg); err == nil {
+					fmt.Printf(">>>>> load: %s %s\n", s.Where.Desc, s.State)
+				}
 			} else {
 				fmt.Printf(">>>>> message invalid: '%s'\n", f)
 			}
@@ -148,10 +735,11 @@ func listenTelegram() error {
 	telegramURL := "https://api.telegram.org/bot"
 	chatID := os.Getenv("GOHOME_CHAT_ID")
 	botToken := os.Getenv("GOHOME_HOME_TALKS_TOKEN")
-	home, err := openHome()
+	home, file, err := openHomeFile()
 	if err != nil {
 		return errors.Wrapf(err, "cannot open Home")
 	}
+	defer close(watchPlant(home, file))
 	listen, _, errs := home.Listen()
 	ok := true
 	for ok {
@@ -160,9 +748,15 @@ func listenTelegram() error {
 			fmt.Printf(">>>>> error received (ok? %t): %v\n", ok, e)
 		case f, ok := <-listen:
 			if mOK, _ := gohome.IsValid(f); mOK {
-				msg := home.Plant.ParseFrame(f)
-				js := home.Plant.FormatToJSON(msg)
+				msg := home.Current().ParseFrame(f)
+				js := home.Current().FormatToJSON(msg)
 				text := fmt.Sprintf("JSON: %s  of FRAME: %s  RECEIVED_OK: %t", js, f, ok)
+				if e, err := gohome.NewAlarmEvent(msg); err == nil && e.IsAlarm() {
+					text = fmt.Sprintf("ALARM! %s  %s", e, text)
+				}
+				if e, err := gohome.NewDoorEvent(msg); err == nil && e.IsCall() {
+					text = fmt.Sprintf("RING! %s  %s", e, text)
+				}
 				fmt.Printf("Message to send-> %s\n", text)
 				v := url.Values{}
 				v.Set("chat_id", chatID)
@@ -177,53 +771,148 @@ func listenTelegram() error {
 	return nil
 }
 
+//confFiles are the names of the configuration file, searched in this order
+var confFiles = []string{defaultConf, "gohome.yaml", "gohome.yml", "gohome.toml"}
+
+//openConfIn opens the first configuration file found in the directory
+func openConfIn(dir string) (*os.File, error) {
+	var first error
+	for _, name := range confFiles {
+		config, err := os.Open(filepath.Join(dir, name))
+		if err == nil {
+			return config, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return nil, first
+}
+
 func openPlantFile() (*os.File, error) {
 	gohomePath, err := os.Executable()
 	if err != nil {
 		return nil, err
 	}
-	gohomeDir := filepath.Dir(gohomePath)
-	plantFilePath := filepath.Join(gohomeDir, defaultConf)
-	config, err := os.Open(plantFilePath)
-	if err != nil {
-		return nil, err
-	}
-	return config, nil
+	return openConfIn(filepath.Dir(gohomePath))
 }
 
 func openSysPlantFile() (*os.File, error) {
 	homePath := os.Getenv("HOME")
-	plantFilePath := filepath.Join(homePath, defaultSysConf)
-	config, err := os.Open(plantFilePath)
+	return openConfIn(filepath.Dir(filepath.Join(homePath, defaultSysConf)))
+}
+
+//setLocale loads the user locale file next to the configuration file and chooses the locale in GOHOME_LOCALE
+func setLocale() error {
+	dirs := []string{filepath.Dir(filepath.Join(os.Getenv("HOME"), defaultSysConf))}
+	if gohomePath, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(gohomePath))
+	}
+	for _, d := range dirs {
+		localeFile, err := os.Open(filepath.Join(d, defaultLocaleConf))
+		if err != nil {
+			continue
+		}
+		err = gohome.LoadLocale(localeFile)
+		localeFile.Close()
+		if err != nil {
+			return errors.Wrapf(err, "cannot load locale file: %s", localeFile.Name())
+		}
+		break
+	}
+	return gohome.SetLocale(os.Getenv("GOHOME_LOCALE"))
+}
+
+//loadWhoFile extends the WHO catalogue with the user file, if present
+func loadWhoFile(path string) error {
+	whoFile, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
-		return nil, err
+		return errors.Wrapf(err, "cannot open WHO file: %s", path)
 	}
-	return config, nil
+	defer whoFile.Close()
+	fmt.Printf("WHO file is: %s \n", path)
+	if err := gohome.LoadWhoCatalogue(whoFile); err != nil {
+		return errors.Wrapf(err, "cannot load WHO file: %s", path)
+	}
+	return nil
 }
 
-func openHome() (*gohome.Home, error) {
+//plantName is the plant chosen with --plant, empty for the default one
+var plantName string
+
+//openHomes loads the plants of the configuration file and returns them with the name of the file
+func openHomes() (*gohome.Homes, string, error) {
 	config, err := openSysPlantFile()
 	if err != nil {
 		config, err = openPlantFile()
 	}
 	if err != nil {
-		return nil, errors.Wrapf(err, "cannot open configuration file: %s", defaultConf)
+		return nil, "", errors.Wrapf(err, "cannot open configuration file: %s", defaultConf)
 	}
 	fmt.Printf("Plant file is: %s \n", config.Name())
 	defer config.Close()
-	plant, err := gohome.NewPlant(config)
+	if err := loadWhoFile(filepath.Join(filepath.Dir(config.Name()), defaultWhoConf)); err != nil {
+		return nil, "", err
+	}
+	newHomes := gohome.NewHomes
+	if os.Getenv("GOHOME_STRICT") != "" {
+		newHomes = gohome.NewStrictHomes
+	}
+	homes, err := newHomes(config)
 	if err != nil {
-		return nil, errors.Wrapf(err, "cannot load plant from configuration file: %s", defaultConf)
+		return nil, "", errors.Wrapf(err, "cannot load plant from configuration file: %s", defaultConf)
 	}
-	home := gohome.NewHome(plant)
-	return home, nil
+	return homes, config.Name(), nil
+}
+
+func openHome() (*gohome.Home, error) {
+	home, _, err := openHomeFile()
+	return home, err
+}
+
+//openHomeFile returns the home chosen with --plant and the name of its configuration file
+func openHomeFile() (*gohome.Home, string, error) {
+	homes, file, err := openHomes()
+	if err != nil {
+		return nil, "", err
+	}
+	home, err := homes.Home(plantName)
+	return home, file, err
+}
+
+//loadPlant reads the plant chosen with --plant from the configuration
+func loadPlant(config io.Reader) (*gohome.Plant, error) {
+	homes, err := gohome.NewHomes(config)
+	if err != nil {
+		return nil, err
+	}
+	home, err := homes.Home(plantName)
+	if err != nil {
+		return nil, err
+	}
+	return home.Current(), nil
+}
+
+//watchPlant reloads the plant of the home when the configuration file changes, keeping the current one if invalid
+func watchPlant(home *gohome.Home, file string) chan<- struct{} {
+	stop, errs := home.Watch(file, reloadInterval, loadPlant)
+	go func() {
+		for err := range errs {
+			fmt.Printf(">>>>> configuration not reloaded: %v\n", err)
+		}
+	}()
+	return stop
 }
 
 func remoteControl() error {
-	home, err := openHome()
+	home, file, err := openHomeFile()
 	if err != nil {
 		return errors.Wrapf(err, "cannot open Home")
 	}
+	defer close(watchPlant(home, file))
 	pubsub, err := gohome.NewPubSub()
 	if err != nil {
 		return errors.Wrapf(err, "cannot access Google Pub/Sub")
@@ -232,7 +921,7 @@ func remoteControl() error {
 	for true {
 		select {
 		case inMsg := <-incoming:
-			fmt.Printf("Received from remote JSON: %s  FRAME: %s \n", home.Plant.FormatToJSON(inMsg), inMsg.Frame())
+			fmt.Printf("Received from remote JSON: %s  FRAME: %s \n", home.Current().FormatToJSON(inMsg), inMsg.Frame())
 			home.Do(inMsg)
 			break
 		case err := <-errs:
@@ -248,26 +937,88 @@ func basicHelp() {
 	fmt.Printf("a simple command line tool to control a Bticino MyHome plant.\n")
 	fmt.Printf("\n")
 	fmt.Printf("Basic commands:\n")
+	fmt.Printf("     %s [--plant <plant>] <command>: run the command on a plant of the configuration, the default one if omitted\n", os.Args[0])
 	fmt.Printf("     %s help: extended help\n", os.Args[0])
-	fmt.Printf("     %s plant: print current plant from file gohome.json\n", os.Args[0])
+	fmt.Printf("     %s plant: print current plant from file gohome.json (or .yaml, .toml)\n", os.Args[0])
 	fmt.Printf("     %s show: show status of all home components\n", os.Args[0])
-	fmt.Printf("     %s listen: listen to network and show events\n", os.Args[0])
+	fmt.Printf("     %s listen: listen to network and show events, reloading the configuration when it changes\n", os.Args[0])
 	fmt.Printf("     %s do: listen to network and show events\n", os.Args[0])
+	fmt.Printf("     %s zone: show and set thermoregulation zones\n", os.Args[0])
+	fmt.Printf("     %s alarm status: show the state of the burglar alarm\n", os.Args[0])
+	fmt.Printf("     %s gateway info: show the gateway information\n", os.Args[0])
+	fmt.Printf("     %s gateway sync-time: set the gateway clock to the local time\n", os.Args[0])
+	fmt.Printf("     %s energy: show the consumption of the meters\n", os.Args[0])
+	fmt.Printf("     %s energy updates <minutes>: stream the power of the meters as events (0 to stop)\n", os.Args[0])
+	fmt.Printf("     %s audio: show and control the sound diffusion zones\n", os.Args[0])
+	fmt.Printf("     %s door open <lock>: open a door lock\n", os.Args[0])
+	fmt.Printf("     %s door light <panel>: switch on the stair light of an entrance panel\n", os.Args[0])
+	fmt.Printf("     %s scenario [list]: list the scenarios of the plant\n", os.Args[0])
+	fmt.Printf("     %s scenario run <name>: activate a scenario\n", os.Args[0])
+	fmt.Printf("     %s diag <where>|zone:<zone>|gateway: show the faults of automation, thermoregulation or gateway devices\n", os.Args[0])
+	fmt.Printf("     %s config validate [<file>]: list the problems of the plant configuration, at their path in the v2 form (lights and shutters are devices)\n", os.Args[0])
+	fmt.Printf("     %s discover [<host>:<port> [<password>]] [json|yaml|toml] > gohome.json: write a draft configuration with the devices on the bus\n", os.Args[0])
+	fmt.Printf("     %s discover merge [json|yaml|toml] > new.json: add the devices on the bus to the current configuration\n", os.Args[0])
 }
 
 func advancedHelp(pars []string) {
 	fmt.Printf("\n")
 	fmt.Printf("ADVANCED HELP\n")
-	fmt.Printf("      Default configuration file is \"gohome.json\"\n\n")
+	fmt.Printf("      Default configuration file is \"gohome.json\", \"gohome.yaml\" or \"gohome.toml\" with the same content\n")
+	fmt.Printf("      WHOs, WHATs and dimensions can be added or renamed in \"who.json\" next to it\n")
+	fmt.Printf("      Names and aliases of WHOs and WHATs can be translated in \"locale.json\" next to it,\n")
+	fmt.Printf("      the locale used to show them is chosen with GOHOME_LOCALE (eg. it), available: %s\n", strings.Join(gohome.Locales(), ", "))
+	fmt.Printf("      It can declare several plants, each with its gateway address and password:\n")
+	fmt.Printf("      {\"default\": \"house\", \"plants\": {\"house\": {..}, \"studio\": {..}}}\n")
+	fmt.Printf("      With GOHOME_STRICT set, a configuration with problems (see config validate) is refused\n\n")
 	fmt.Printf("      To perform action on the plant:\n\n")
 	fmt.Printf("      $ %s do <who> <what> <where>\n", os.Args[0])
-	fmt.Printf("             who:   LIGHT (currently work only on lights)\n")
+	helpWho := []string{"LIGHT", "AUTOMATION", "THERMOREGULATION", "CEN_PLUS", "LOAD_CONTROL", "SOUND", "SOUND_SYSTEM", "DOOR_ENTRY", "AUX"}
+	names := make([]string, 0, len(helpWho))
+	for _, w := range helpWho {
+		if who, err := gohome.LookupWho(w); err == nil {
+			names = append(names, who.LocalDesc())
+		}
+	}
+	fmt.Printf("             who:   %s\n", strings.Join(names, ", "))
 	fmt.Printf("             what:  <command>\n")
 	fmt.Printf("             where: <room>.<light> (in case of single light)\n")
+	fmt.Printf("             where: <room>.<shutter> (in case of single shutter)\n")
 	fmt.Printf("             where: <room>         (in case of ambient)\n")
+	fmt.Printf("             where: group:<group>  (in case of group)\n")
+	fmt.Printf("             where: <floor>        (in case of floor, all its ambients)\n")
+	fmt.Printf("             where: <floor>/<room>[.<light>] (in case of room of a floor)\n")
+	fmt.Printf("             where: zone:<zone>    (in case of thermoregulation zone)\n")
+	fmt.Printf("             where: alarm:<zone>   (in case of alarm zone, read only)\n")
+	fmt.Printf("             where: keypad:<keypad> (in case of CEN/CEN+ keypad, button as parameter: SHORT_PRESS#<button>)\n")
+	fmt.Printf("             where: load:<load>    (in case of load actuator)\n")
+	fmt.Printf("             where: aux:<channel>  (in case of auxiliary channel)\n")
+	fmt.Printf("             where: audio:<zone>[.<speaker>], source:<source> (in case of sound diffusion)\n")
+	fmt.Printf("             where: panel:<panel>, lock:<lock> (in case of video door entry)\n")
+	fmt.Printf("             where: module:<module>, scenario:<scenario> (in case of scenario module or MH200N)\n")
 	fmt.Printf("             where: general        (in case of general)\n")
-	fmt.Printf("\n\nFor LIGHT <command> is one of:\n")
-	for _, v := range gohome.NewWho("LIGHT").Actions {
-		fmt.Printf("      %v\n", v)
+	fmt.Printf("             where: <plant>@<where> (in case of where of another plant, chosen by default where found)\n")
+	fmt.Printf("      <what> and <where> accept OpenWebNet parameters after a '#': SET_50#3 kitchen.main#4#01\n")
+	fmt.Printf("\n      To read and control the heating:\n\n")
+	fmt.Printf("      $ %s zone [show [<zone>..]]\n", os.Args[0])
+	fmt.Printf("      $ %s zone set <zone> <temperature> [heating|cooling|generic]\n", os.Args[0])
+	fmt.Printf("      $ %s zone mode <zone> <mode>\n", os.Args[0])
+	fmt.Printf("\n      To control the sound diffusion (zone is <zone> or <zone>.<speaker>):\n\n")
+	fmt.Printf("      $ %s audio [show]\n", os.Args[0])
+	fmt.Printf("      $ %s audio on|off <zone>\n", os.Args[0])
+	fmt.Printf("      $ %s audio source <zone> <source>\n", os.Args[0])
+	fmt.Printf("      $ %s audio volume <zone> <0-31|+N|-N>\n", os.Args[0])
+	fmt.Printf("\n      To manage the scenarios:\n\n")
+	fmt.Printf("      $ %s scenario [list]\n", os.Args[0])
+	fmt.Printf("      $ %s scenario run|stop|enable|disable <name> (stop only on MH200N, enable and disable lock the whole scenario module)\n", os.Args[0])
+	fmt.Printf("      $ %s scenario record|end-record|erase <name> (only on scenario modules)\n", os.Args[0])
+	for _, w := range helpWho {
+		who, err := gohome.LookupWho(w)
+		if err != nil {
+			continue
+		}
+		fmt.Printf("\n\nFor %s <command> is one of:\n", who.LocalDesc())
+		for _, v := range who.Actions {
+			fmt.Printf("      %v\n", who.LocalWhat(v))
+		}
 	}
 }
@@ -0,0 +1,146 @@
+package gohome
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+//ErrDeviceNotFound is returned when the desired device is not found in the ambient
+var ErrDeviceNotFound = errors.New("device not found")
+
+//PLANT_VERSION is the version of the plant configuration written by ExportPlant
+const PLANT_VERSION = 2
+
+//Types of device
+const DEVICE_LIGHT = "light"
+const DEVICE_DIMMER = "dimmer"
+const DEVICE_RELAY = "relay"
+const DEVICE_SHUTTER = "shutter"
+const DEVICE_PROBE = "probe"
+
+//deviceDefaults are the WHO and the capabilities of each type of device, used when not given in the configuration
+var deviceDefaults = map[string]Device{
+	DEVICE_LIGHT:   {Who: "1", Capabilities: []string{"on_off"}},
+	DEVICE_DIMMER:  {Who: "1", Capabilities: []string{"on_off", "level"}},
+	DEVICE_RELAY:   {Who: "1", Capabilities: []string{"on_off"}},
+	DEVICE_SHUTTER: {Who: "2", Capabilities: []string{"up_down"}},
+	DEVICE_PROBE:   {Who: "4", Capabilities: []string{"temperature"}},
+}
+
+//Device is a named device of an ambient. Address is the point of the ambient (1-15) for lights and shutters,
+//the zone (1-99) for probes, the channel (1-9) for aux. Only the points can be used as <ambient>.<device> where.
+type Device struct {
+	Type         string    `json:"type"`
+	Who          string    `json:"who"`
+	Address      string    `json:"address"`
+	Capabilities []string  `json:"capabilities,omitempty"`
+	Metadata     *Metadata `json:"metadata,omitempty"`
+}
+
+//Metadata is optional information about a device used by user interfaces
+type Metadata struct {
+	Icon        string `json:"icon,omitempty"`
+	Floor       string `json:"floor,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+//NewDevice returns a device of the given type at the address with the default WHO and capabilities
+func NewDevice(kind string, address string) Device {
+	d := Device{Type: kind, Address: address}
+	d.setDefaults()
+	return d
+}
+
+//setDefaults fills WHO and capabilities of the device from its type
+func (d *Device) setDefaults() {
+	def, ok := deviceDefaults[d.Type]
+	if !ok {
+		return
+	}
+	if d.Who == "" {
+		d.Who = def.Who
+	}
+	if d.Capabilities == nil {
+		d.Capabilities = append([]string{}, def.Capabilities...)
+	}
+}
+
+//HasCapability returns true if the device declares the capability
+func (d Device) HasCapability(c string) bool {
+	for _, dc := range d.Capabilities {
+		if dc == c {
+			return true
+		}
+	}
+	return false
+}
+
+//isPoint returns true if the device is addressed as a point of its ambient
+func (d Device) isPoint() bool {
+	return d.Who == whoLight().Code || d.Who == whoAutomation().Code
+}
+
+//pointNum returns the point of the ambient the device is connected to
+func (d Device) pointNum() (int, bool) {
+	if !d.isPoint() {
+		return 0, false
+	}
+	n, err := strconv.Atoi(d.Address)
+	return n, err == nil
+}
+
+//code returns the WHERE code of the device in the ambient
+func (d Device) code(a Ambient) (string, error) {
+	if !d.isPoint() {
+		return d.Address, nil
+	}
+	n, ok := d.pointNum()
+	if !ok {
+		return "", errors.Wrapf(ErrInvalidAddress, "point '%s' is not a number", d.Address)
+	}
+	return pointCode(a.Num, n)
+}
+
+//migrate moves the lights and shutters of a v1 ambient to its devices
+func (a *Ambient) migrate() {
+	if len(a.Lights) == 0 && len(a.Shutters) == 0 {
+		return
+	}
+	if a.Devices == nil {
+		a.Devices = map[string]Device{}
+	}
+	for name, pl := range a.Lights {
+		a.Devices[name] = NewDevice(DEVICE_LIGHT, strconv.Itoa(pl))
+	}
+	for name, pl := range a.Shutters {
+		a.Devices[name] = NewDevice(DEVICE_SHUTTER, strconv.Itoa(pl))
+	}
+	a.Lights, a.Shutters = nil, nil
+}
+
+//migrate converts a v1 plant, where ambients have lights and shutters, to the current version
+func (p *Plant) migrate() {
+	for ka, a := range p.Ambients {
+		a.migrate()
+		for kd, d := range a.Devices {
+			d.setDefaults()
+			a.Devices[kd] = d
+		}
+		p.Ambients[ka] = a
+	}
+	p.Version = PLANT_VERSION
+}
+
+//Device returns the device of the ambient with the given name
+func (p *Plant) Device(ambient string, name string) (Device, error) {
+	a, ok := p.Ambients[ambient]
+	if !ok {
+		return Device{}, ErrAmbientNotFound
+	}
+	d, ok := a.Devices[name]
+	if !ok {
+		return Device{}, ErrDeviceNotFound
+	}
+	return d, nil
+}
@@ -0,0 +1,104 @@
+package gohome
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrNotDiagnostic is returned when a message is not a diagnostic report
+var ErrNotDiagnostic = errors.New("message is not a diagnostic report")
+
+//DIAGNOSTIC is the dimension carrying the fault bits of a device
+const DIAGNOSTIC Dimension = "0"
+
+//diagnosticWho maps the codes of the diagnosed WHOs to the codes of their diagnostic WHO
+var diagnosticWho = map[string]string{
+	"2":  "1001",
+	"4":  "1004",
+	"13": "1013",
+}
+
+//DiagnosticReport is what a device reports about its faults. Faults are the positions of the flags set, from 0
+//for the first one: their meaning depends on the device, so they are not named. An empty Faults means the device
+//is working.
+type DiagnosticReport struct {
+	Where  Where
+	Who    string
+	Faults []int
+}
+
+//NewDiagnosticReport decodes the DIAGNOSTIC response of a diagnostic WHO: each value is a string of 0/1 flags
+func NewDiagnosticReport(msg Message) (DiagnosticReport, error) {
+	if msg.Who == nil || msg.Kind != DIMENSIONRESPONSE || msg.Dimension != DIAGNOSTIC || len(msg.Values) == 0 {
+		return DiagnosticReport{}, ErrNotDiagnostic
+	}
+	if !isDiagnosticWho(msg.Who.Code) {
+		return DiagnosticReport{}, errors.Wrapf(ErrNotDiagnostic, "WHO %s is not a diagnostic WHO", msg.Who.Code)
+	}
+	report := DiagnosticReport{Where: msg.Where, Who: msg.Who.Desc, Faults: []int{}}
+	bits := ""
+	for _, v := range msg.Values {
+		bits += string(v)
+	}
+	for i, b := range bits {
+		switch {
+		case b == '0':
+		case b != '1':
+			return DiagnosticReport{}, errors.Wrapf(ErrNotDiagnostic, "wrong diagnostic flags %s", bits)
+		default:
+			report.Faults = append(report.Faults, i)
+		}
+	}
+	return report, nil
+}
+
+//isDiagnosticWho returns true if the code is of a diagnostic WHO
+func isDiagnosticWho(code string) bool {
+	for _, d := range diagnosticWho {
+		if d == code {
+			return true
+		}
+	}
+	return false
+}
+
+//IsOK returns true if the device reports no fault
+func (r DiagnosticReport) IsOK() bool {
+	return len(r.Faults) == 0
+}
+
+//String returns a human readable description of the report
+func (r DiagnosticReport) String() string {
+	if r.IsOK() {
+		return fmt.Sprintf("%s '%s': OK", r.Who, r.Where.Desc)
+	}
+	flags := make([]string, len(r.Faults))
+	for i, f := range r.Faults {
+		flags[i] = strconv.Itoa(f)
+	}
+	return fmt.Sprintf("%s '%s': faults at flags %s", r.Who, r.Where.Desc, strings.Join(flags, ", "))
+}
+
+//Diagnose asks the devices at the given where of the WHO (AUTOMATION, THERMOREGULATION or GATEWAY) their diagnostic
+func (h *Home) Diagnose(who *Who, where Where) ([]DiagnosticReport, error) {
+	code, ok := diagnosticWho[who.Code]
+	if !ok {
+		return nil, errors.Errorf("no diagnostic available for %s", who.Desc)
+	}
+	answers, err := h.Ask(NewDimensionRequest(defaultWho(code), where, DIAGNOSTIC))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get diagnostic of %s", where.Desc)
+	}
+	reports := make([]DiagnosticReport, 0, len(answers))
+	for _, a := range answers {
+		r, err := NewDiagnosticReport(a)
+		if err != nil {
+			continue
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
@@ -0,0 +1,42 @@
+package gohome_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestDiagnosticReport(t *testing.T) {
+	plant := loadTestPlant(t)
+	reports := map[string]string{
+		"*#1001*25*0*100100##": "AUTOMATION_DIAGNOSTIC 'living.window': faults at flags 0, 3",
+		"*#1001*26*0*000000##": "AUTOMATION_DIAGNOSTIC 'living.door': OK",
+		"*#1004*1*0*0010000##": "THERMOREGULATION_DIAGNOSTIC 'zone:day': faults at flags 2",
+		"*#1013**0*00001001##": "GATEWAY_DIAGNOSTIC '': faults at flags 4, 7",
+	}
+	for f, exp := range reports {
+		r, err := gohome.NewDiagnosticReport(plant.ParseFrame(f))
+		if err != nil {
+			t.Errorf("diagnostic not decoded from %s: %v", f, err)
+			continue
+		}
+		if r.String() != exp {
+			t.Errorf("wrong diagnostic from %s: %s", f, r)
+		}
+	}
+	for _, f := range []string{"*#1001*25*0*102##", "*#4*1*0*0210##", "*#1001*25*0##"} {
+		if r, err := gohome.NewDiagnosticReport(plant.ParseFrame(f)); err == nil {
+			t.Errorf("%s should not be a diagnostic: %+v", f, r)
+		}
+	}
+}
+
+func TestDiagnosticReadOnly(t *testing.T) {
+	for _, w := range []string{"AUTOMATION_DIAGNOSTIC", "THERMOREGULATION_DIAGNOSTIC", "GATEWAY_DIAGNOSTIC"} {
+		who := gohome.NewWho(w)
+		if who.Desc != w || !who.ReadOnly || !strings.HasPrefix(who.Code, "10") {
+			t.Errorf("wrong diagnostic WHO %s: %v", w, who)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package gohome
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+//discoveryWhos are the WHOs whose devices are found asking their status, with the type of device to create
+var discoveryWhos = []struct {
+	who  func() *Who
+	kind string
+}{
+	{who: whoLight, kind: DEVICE_LIGHT},
+	{who: whoAutomation, kind: DEVICE_SHUTTER},
+}
+
+//discoveryRequests returns the status requests sent to find the devices: QUERY_ALL, the general request of each
+//WHO and, as not all the gateways answer the general ones for every device, the request of each ambient
+func discoveryRequests() []Message {
+	requests := []Message{SystemMessages["QUERY_ALL"]}
+	for _, dw := range discoveryWhos {
+		requests = append(requests, NewRequest(dw.who(), What{}, GENERAL))
+	}
+	requests = append(requests, NewRequest(whoAux(), What{}, GENERAL))
+	for amb := 0; amb <= 10; amb++ {
+		code, _ := ambientCode(amb)
+		for _, dw := range discoveryWhos {
+			requests = append(requests, NewRequest(dw.who(), What{}, Where{Code: code}))
+		}
+	}
+	return requests
+}
+
+//Discover asks the status of the devices on the bus and returns a draft plant with the answering ones, named
+//after their addresses. With merge the draft extends the current plant, keeping the names already given.
+func (h *Home) Discover(merge bool) (*Plant, error) {
+	answers := []Message{}
+	for _, r := range discoveryRequests() {
+		a, err := h.Ask(r)
+		if err != nil {
+			log.Printf("Home.Discover - no answer to %v: %v", r, err)
+			continue
+		}
+		answers = append(answers, a...)
+	}
+	if len(answers) == 0 {
+		return nil, errors.Wrapf(ErrNoData, "no device answered")
+	}
+	return h.Current().Discovered(answers, merge)
+}
+
+//Discovered returns a plant with the devices that sent the given status messages. Without merge only the gateway
+//of the plant and its password are kept, with merge the known ambients and devices keep their names and the new
+//ones are added.
+func (p *Plant) Discovered(answers []Message, merge bool) (*Plant, error) {
+	draft := &Plant{Version: PLANT_VERSION, Name: p.Name, Num: p.Num, Address: p.Address, Password: p.Password}
+	if merge {
+		var err error
+		if draft, err = p.copy(); err != nil {
+			return nil, errors.Wrapf(err, "cannot copy the plant to merge")
+		}
+	}
+	if draft.Ambients == nil {
+		draft.Ambients = map[string]Ambient{}
+	}
+	for _, a := range answers {
+		if a.Who == nil || a.Kind != COMMAND {
+			continue
+		}
+		if a.Who.Code == whoAux().Code {
+			draft.discoverAux(a.Where.Code)
+			continue
+		}
+		for _, dw := range discoveryWhos {
+			if dw.who().Code != a.Who.Code {
+				continue
+			}
+			kind := dw.kind
+			if n, err := strconv.Atoi(a.What.Code); err == nil && kind == DEVICE_LIGHT && n >= 2 && n <= 10 {
+				kind = DEVICE_DIMMER
+			}
+			if err := draft.discoverDevice(kind, a.Where); err != nil {
+				log.Printf("Plant.Discovered - device of %s at %s ignored: %v", a.Who.Desc, a.Where.Code, err)
+			}
+		}
+	}
+	return draft, nil
+}
+
+//copy returns a deep copy of the plant
+func (p *Plant) copy() (*Plant, error) {
+	var buf bytes.Buffer
+	if err := p.ExportPlant(&buf); err != nil {
+		return nil, err
+	}
+	return decodePlant(&buf)
+}
+
+//discoverDevice adds the ambient of the where and, for a point, its device when not already in the plant
+func (p *Plant) discoverDevice(kind string, where Where) error {
+	addr, err := parseAddress(where.Code)
+	if err != nil {
+		return err
+	}
+	if addr.general || addr.group > 0 {
+		return nil
+	}
+	iface := busInterface(where.Params)
+	name, amb := p.ambient(addr.ambient, iface)
+	if addr.point > 0 {
+		if _, known := amb.pointName(addr.point); !known {
+			dev := NewDevice(kind, strconv.Itoa(addr.point))
+			dname := placeholder(fmt.Sprintf("%s_%d", kind, addr.point), func(n string) bool {
+				_, ok := amb.Devices[n]
+				return ok
+			})
+			amb.Devices[dname] = dev
+		}
+	}
+	p.Ambients[name] = amb
+	return nil
+}
+
+//ambient returns the ambient with the number on the interface, creating it when not in the plant
+func (p *Plant) ambient(num int, iface string) (string, Ambient) {
+	for ka, a := range p.Ambients {
+		if a.Num == num && a.Interface == iface {
+			if a.Devices == nil {
+				a.Devices = map[string]Device{}
+			}
+			return ka, a
+		}
+	}
+	name := fmt.Sprintf("ambient_%d", num)
+	if iface != "" {
+		name = fmt.Sprintf("%s_%s", name, iface)
+	}
+	name = placeholder(name, func(n string) bool {
+		_, ok := p.Ambients[n]
+		return ok
+	})
+	return name, Ambient{Num: num, Interface: iface, Devices: map[string]Device{}}
+}
+
+//discoverAux adds the auxiliary channel with the code when not already in the plant
+func (p *Plant) discoverAux(code string) {
+	n, err := strconv.Atoi(code)
+	if err != nil || n < 1 || n > 9 {
+		return
+	}
+	for _, a := range p.Aux {
+		if a == n {
+			return
+		}
+	}
+	if p.Aux == nil {
+		p.Aux = map[string]int{}
+	}
+	name := placeholder(fmt.Sprintf("aux_%d", n), func(n string) bool {
+		_, ok := p.Aux[n]
+		return ok
+	})
+	p.Aux[name] = n
+}
+
+//placeholder returns the name, with a numeric suffix if already taken
+func placeholder(name string, taken func(string) bool) string {
+	unique := name
+	for i := 2; taken(unique); i++ {
+		unique = fmt.Sprintf("%s_%d", name, i)
+	}
+	return unique
+}
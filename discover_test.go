@@ -0,0 +1,101 @@
+package gohome_test
+
+import (
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+var discoveryFrames = []string{
+	"*1*1*12##",
+	"*1*0*21##",
+	"*1*5*35##",
+	"*1*0*0311##",
+	"*1*1*1003#4#01##",
+	"*2*0*25##",
+	"*2*1*27##",
+	"*9*1*4##",
+	"*9*0*7##",
+	"*#4*1*0*0215##",
+}
+
+func discoveryAnswers(plant *gohome.Plant) []gohome.Message {
+	answers := make([]gohome.Message, len(discoveryFrames))
+	for i, f := range discoveryFrames {
+		answers[i] = plant.ParseFrame(f)
+	}
+	return answers
+}
+
+func TestDiscovered(t *testing.T) {
+	plant := loadTestPlant(t)
+	plant.Password = "12345"
+	draft, err := plant.Discovered(discoveryAnswers(plant), false)
+	if err != nil {
+		t.Fatalf("Discovery failed: %v", err)
+	}
+	if draft.Address != plant.Address || draft.Password != plant.Password || len(draft.Groups) > 0 {
+		t.Errorf("draft should keep only the gateway: %+v", draft)
+	}
+	expected := map[string]string{
+		"ambient_1.light_2":     "12",
+		"ambient_2.light_1":     "21",
+		"ambient_2.shutter_5":   "25",
+		"ambient_2.shutter_7":   "27",
+		"ambient_3.dimmer_5":    "35",
+		"ambient_3.light_11":    "0311",
+		"ambient_10_01.light_3": "1003",
+		"aux:aux_4":             "4",
+		"aux:aux_7":             "7",
+	}
+	checkDiscovered(t, draft, expected)
+	if len(draft.Ambients) != 4 || len(draft.Aux) != 2 {
+		t.Errorf("wrong number of ambients or aux: %v %v", draft.Ambients, draft.Aux)
+	}
+	if d, _ := draft.Device("ambient_3", "dimmer_5"); d.Type != gohome.DEVICE_DIMMER || d.Who != "1" {
+		t.Errorf("wrong dimmer: %+v", d)
+	}
+}
+
+func TestDiscoveredMerge(t *testing.T) {
+	plant := loadTestPlant(t)
+	draft, err := plant.Discovered(discoveryAnswers(plant), true)
+	if err != nil {
+		t.Fatalf("Discovery failed: %v", err)
+	}
+	expected := map[string]string{
+		"kitchen.main":       "12",
+		"kitchen.table":      "11",
+		"living.sofa":        "21",
+		"living.window":      "25",
+		"living.shutter_7":   "27",
+		"ambient_3.dimmer_5": "35",
+		"ambient_3.light_11": "0311",
+		"studio.desk":        "1005",
+		"studio.light_3":     "1003",
+		"group:downstairs":   "#1",
+		"aux:siren":          "4",
+		"aux:aux_7":          "7",
+	}
+	checkDiscovered(t, draft, expected)
+	if len(draft.Aux) != 3 {
+		t.Errorf("wrong aux: %v", draft.Aux)
+	}
+	if _, ok := plant.Ambients["ambient_3"]; ok {
+		t.Errorf("merge changed the original plant")
+	}
+}
+
+func checkDiscovered(t *testing.T, draft *gohome.Plant, expected map[string]string) {
+	t.Helper()
+	for desc, code := range expected {
+		where, err := draft.WhereFromDesc(desc)
+		if err != nil {
+			t.Errorf("%s not discovered: %v", desc, err)
+			continue
+		}
+		if where.Code != code {
+			t.Errorf("wrong code for %s: %s instead of %s", desc, where.Code, code)
+		}
+	}
+}
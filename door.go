@@ -0,0 +1,110 @@
+package gohome
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrPanelNotFound is returned when the desired entrance panel or door lock is not found in the conf file
+var ErrPanelNotFound = errors.New("entrance panel or lock not found")
+
+//ErrNotDoorEvent is returned when a message is not a video door entry event
+var ErrNotDoorEvent = errors.New("message is not a door entry event")
+
+//panelPrefix introduces an entrance panel in a WHERE description: panel:<name>
+const panelPrefix = "panel:"
+
+//lockPrefix introduces a door lock in a WHERE description: lock:<name>
+const lockPrefix = "lock:"
+
+//DoorEvent is an event of the video door entry system, eg. a CALL from an entrance panel
+type DoorEvent struct {
+	Where  Where
+	Action string
+}
+
+//NewDoorEvent decodes a video door entry message
+func NewDoorEvent(msg Message) (DoorEvent, error) {
+	if msg.Who == nil || msg.Who.Code != whoDoorEntry().Code || msg.Kind != COMMAND || msg.What.Desc == "" {
+		return DoorEvent{}, ErrNotDoorEvent
+	}
+	return DoorEvent{Where: msg.Where, Action: msg.What.Desc}, nil
+}
+
+//IsCall returns true if someone rang at an entrance panel
+func (e DoorEvent) IsCall() bool {
+	return e.Action == "CALL"
+}
+
+//String returns a human readable description of the event
+func (e DoorEvent) String() string {
+	return fmt.Sprintf("%s from '%s'", e.Action, e.Where.Desc)
+}
+
+//doorCode returns the WHERE code of the entrance panel N: 40NN
+func doorCode(n int) (string, error) {
+	if n < 0 || n > 99 {
+		return "", errors.Wrapf(ErrInvalidAddress, "entrance panel %d is not in 0-99", n)
+	}
+	return fmt.Sprintf("40%02d", n), nil
+}
+
+//panelCode returns the WHERE code of the entrance panel with the given name
+func (p *Plant) panelCode(name string) (string, error) {
+	n, ok := p.Panels[name]
+	if !ok {
+		return "", ErrPanelNotFound
+	}
+	return doorCode(n)
+}
+
+//lockCode returns the WHERE code of the door lock with the given name, that is the code of the panel driving it
+func (p *Plant) lockCode(name string) (string, error) {
+	n, ok := p.Locks[name]
+	if !ok {
+		return "", ErrPanelNotFound
+	}
+	return doorCode(n)
+}
+
+//doorFromCode decodes the WHERE of a video door entry frame, panels are preferred to locks with the same number
+func (p *Plant) doorFromCode(code string) (Where, error) {
+	if code == "" {
+		return Where{}, nil
+	}
+	code, params := splitParams(code)
+	where := Where{Code: code, Params: params}
+	if len(code) != 4 || !strings.HasPrefix(code, "40") {
+		return where, nil
+	}
+	n, err := strconv.Atoi(code[2:])
+	if err != nil {
+		return Where{}, errors.Wrapf(ErrWhereNotInPlant, "where: %v", code)
+	}
+	for kl, l := range p.Locks {
+		if l == n {
+			where.Desc = lockPrefix + kl
+		}
+	}
+	for kp, pn := range p.Panels {
+		if pn == n {
+			where.Desc = panelPrefix + kp
+		}
+	}
+	return where, nil
+}
+
+//OpenLock opens the door lock
+func (h *Home) OpenLock(lock Where) error {
+	what, _ := whoDoorEntry().WhatFromDesc("DOOR_LOCK_OPEN")
+	return h.Do(NewCommand(whoDoorEntry(), what, lock))
+}
+
+//StairLight switches on the stair light through the entrance panel
+func (h *Home) StairLight(panel Where) error {
+	what, _ := whoDoorEntry().WhatFromDesc("STAIR_LIGHT_ON")
+	return h.Do(NewCommand(whoDoorEntry(), what, panel))
+}
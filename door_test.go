@@ -0,0 +1,59 @@
+package gohome_test
+
+import (
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestDoorEvent(t *testing.T) {
+	plant := loadTestPlant(t)
+	events := map[string]gohome.DoorEvent{
+		"*6*1*4001##":  gohome.DoorEvent{Action: "CALL", Where: gohome.Where{Desc: "panel:front"}},
+		"*6*10*4000##": gohome.DoorEvent{Action: "DOOR_LOCK_OPEN", Where: gohome.Where{Desc: "panel:gate"}},
+		"*6*10*4002##": gohome.DoorEvent{Action: "DOOR_LOCK_OPEN", Where: gohome.Where{Desc: "lock:garage"}},
+	}
+	for f, exp := range events {
+		e, err := gohome.NewDoorEvent(plant.ParseFrame(f))
+		if err != nil {
+			t.Errorf("door event not decoded from %s: %v", f, err)
+			continue
+		}
+		if e.Action != exp.Action || e.Where.Desc != exp.Where.Desc {
+			t.Errorf("wrong door event from %s: %+v", f, e)
+		}
+	}
+	e, _ := gohome.NewDoorEvent(plant.ParseFrame("*6*1*4001##"))
+	if !e.IsCall() || e.String() != "CALL from 'panel:front'" {
+		t.Errorf("wrong call event: %s", e)
+	}
+	if e, err := gohome.NewDoorEvent(plant.ParseFrame("*1*1*21##")); err == nil {
+		t.Errorf("light message should not be a door event: %+v", e)
+	}
+}
+
+func TestDoorWhere(t *testing.T) {
+	plant := loadTestPlant(t)
+	who := gohome.NewWho("DOOR_ENTRY")
+	frames := map[string]string{
+		"lock:garage": "*6*10*4002##",
+		"panel:front": "*6*10*4001##",
+	}
+	open, err := who.WhatFromDesc("DOOR_LOCK_OPEN")
+	if err != nil {
+		t.Errorf("What not found: %v", err)
+	}
+	for desc, exp := range frames {
+		where, err := plant.WhereFromDesc(desc)
+		if err != nil {
+			t.Errorf("Where %s not found: %v", desc, err)
+			continue
+		}
+		if frame := gohome.NewCommand(who, open, where).Frame(); frame != exp {
+			t.Errorf("wrong frame for %s: %s", desc, frame)
+		}
+	}
+	if _, err := plant.WhereFromDesc("lock:cellar"); err == nil {
+		t.Errorf("unknown lock should not be found")
+	}
+}
@@ -0,0 +1,217 @@
+package gohome
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrMeterNotFound is returned when the desired energy meter is not found in the conf file
+var ErrMeterNotFound = errors.New("meter not found")
+
+//ErrLoadNotFound is returned when the desired load actuator is not found in the conf file
+var ErrLoadNotFound = errors.New("load not found")
+
+//ErrNotLoadStatus is returned when a message does not carry the state of a load actuator
+var ErrNotLoadStatus = errors.New("message is not a load status")
+
+//ErrNotEnergyReading is returned when a message does not carry an energy or power reading
+var ErrNotEnergyReading = errors.New("message is not an energy reading")
+
+//meterPrefix introduces an energy meter in a WHERE description: meter:<name>
+const meterPrefix = "meter:"
+
+//loadPrefix introduces a load actuator in a WHERE description: load:<name>
+const loadPrefix = "load:"
+
+//Energy dimensions
+const ACTIVE_POWER Dimension = "113"
+const TOTAL_ENERGY Dimension = "51"
+const CURRENT_MONTH_ENERGY Dimension = "53"
+const CURRENT_DAY_ENERGY Dimension = "54"
+const AUTOMATIC_POWER_UPDATE Dimension = "1200#1"
+
+//EnergyReading is a power (W) or energy (Wh) value read from a meter
+type EnergyReading struct {
+	Where     Where
+	Dimension Dimension
+	Desc      string
+	Value     int
+}
+
+//NewEnergyReading decodes an energy management dimension response
+func NewEnergyReading(msg Message) (EnergyReading, error) {
+	if msg.Who == nil || msg.Who.Code != whoEnergy().Code || msg.Kind != DIMENSIONRESPONSE || len(msg.Values) == 0 {
+		return EnergyReading{}, ErrNotEnergyReading
+	}
+	dim, _ := splitParams(string(msg.Dimension))
+	desc, ok := whoEnergy().Dimensions[dim]
+	if !ok {
+		return EnergyReading{}, errors.Wrapf(ErrNotEnergyReading, "unknown dimension %s", msg.Dimension)
+	}
+	v, err := strconv.Atoi(string(msg.Values[0]))
+	if err != nil {
+		return EnergyReading{}, errors.Wrapf(ErrNotEnergyReading, "wrong value %s", msg.Values[0])
+	}
+	return EnergyReading{Where: msg.Where, Dimension: msg.Dimension, Desc: desc, Value: v}, nil
+}
+
+//IsPower returns true if the reading is an instantaneous power in W, false if it is an energy in Wh
+func (r EnergyReading) IsPower() bool {
+	return r.Dimension == ACTIVE_POWER
+}
+
+//String returns the reading with its unit
+func (r EnergyReading) String() string {
+	if r.IsPower() {
+		return fmt.Sprintf("%s %s: %d W", r.Where.Desc, r.Desc, r.Value)
+	}
+	return fmt.Sprintf("%s %s: %.3f kWh", r.Where.Desc, r.Desc, float64(r.Value)/1000)
+}
+
+//LoadStatus is the state of a load actuator: LOAD_ENABLED, LOAD_DISABLED, LOAD_FORCED or END_FORCED
+type LoadStatus struct {
+	Where Where
+	State string
+}
+
+//NewLoadStatus decodes a load control event or status answer
+func NewLoadStatus(msg Message) (LoadStatus, error) {
+	if msg.Who == nil || msg.Who.Code != whoLoad().Code || msg.Kind != COMMAND || msg.What.Desc == "" {
+		return LoadStatus{}, ErrNotLoadStatus
+	}
+	return LoadStatus{Where: msg.Where, State: msg.What.Desc}, nil
+}
+
+//meterCode returns the WHERE code of the meter with the given name: 5N
+func (p *Plant) meterCode(name string) (string, error) {
+	n, ok := p.Meters[name]
+	if !ok {
+		return "", ErrMeterNotFound
+	}
+	if n < 1 || n > 255 {
+		return "", errors.Wrapf(ErrInvalidAddress, "meter %d is not in 1-255", n)
+	}
+	return fmt.Sprintf("5%d", n), nil
+}
+
+//loadCode returns the WHERE code of the load actuator with the given name: 7N#0
+func (p *Plant) loadCode(name string) (string, error) {
+	n, ok := p.Loads[name]
+	if !ok {
+		return "", ErrLoadNotFound
+	}
+	if n < 1 || n > 255 {
+		return "", errors.Wrapf(ErrInvalidAddress, "load %d is not in 1-255", n)
+	}
+	return fmt.Sprintf("7%d#0", n), nil
+}
+
+//energyFromCode decodes the WHERE of an energy management frame: 5N for meters, 7N#0 for actuators
+func (p *Plant) energyFromCode(code string) (Where, error) {
+	if code == "" {
+		return Where{}, nil
+	}
+	code, params := splitParams(code)
+	where := Where{Code: code, Params: params}
+	n, err := strconv.Atoi(code)
+	if err != nil || len(code) < 2 {
+		return Where{}, errors.Wrapf(ErrWhereNotInPlant, "where: %v", code)
+	}
+	num, _ := strconv.Atoi(code[1:])
+	devices, prefix := p.Meters, meterPrefix
+	if strings.HasPrefix(code, "7") {
+		devices, prefix = p.Loads, loadPrefix
+	} else if !strings.HasPrefix(code, "5") {
+		log.Printf("energy where %d is neither a meter nor an actuator", n)
+		return where, nil
+	}
+	for kd, d := range devices {
+		if d == num {
+			where.Desc = prefix + kd
+		}
+	}
+	return where, nil
+}
+
+func (h *Home) askEnergy(meter Where, dim Dimension) (int, error) {
+	answer, err := h.askDimension(whoEnergy(), meter, dim)
+	if err != nil {
+		return 0, err
+	}
+	r, err := NewEnergyReading(answer)
+	if err != nil {
+		return 0, err
+	}
+	return r.Value, nil
+}
+
+//ActivePower reads the instantaneous active power in W measured by the meter
+func (h *Home) ActivePower(meter Where) (int, error) {
+	return h.askEnergy(meter, ACTIVE_POWER)
+}
+
+//EnergyTotal reads the total energy in Wh measured by the meter
+func (h *Home) EnergyTotal(meter Where) (int, error) {
+	return h.askEnergy(meter, TOTAL_ENERGY)
+}
+
+//EnergyToday reads the energy in Wh measured by the meter in the current day
+func (h *Home) EnergyToday(meter Where) (int, error) {
+	return h.askEnergy(meter, CURRENT_DAY_ENERGY)
+}
+
+//EnergyThisMonth reads the energy in Wh measured by the meter in the current month
+func (h *Home) EnergyThisMonth(meter Where) (int, error) {
+	return h.askEnergy(meter, CURRENT_MONTH_ENERGY)
+}
+
+//StartPowerUpdates asks the meter to send its active power as event for the given minutes (1-255)
+func (h *Home) StartPowerUpdates(meter Where, minutes int) error {
+	if minutes < 1 || minutes > 255 {
+		return errors.Errorf("power updates duration %d is not in 1-255 minutes", minutes)
+	}
+	return h.Do(NewDimensionWrite(whoEnergy(), meter, AUTOMATIC_POWER_UPDATE, Value(strconv.Itoa(minutes))))
+}
+
+//StopPowerUpdates stops the active power events of the meter
+func (h *Home) StopPowerUpdates(meter Where) error {
+	return h.Do(NewDimensionWrite(whoEnergy(), meter, AUTOMATIC_POWER_UPDATE, "0"))
+}
+
+//loadActions are the energy management actions forcing a load actuator, by the state of the load control WHO
+var loadActions = map[string]string{
+	"LOAD_FORCED": "FORCE_ON",
+	"END_FORCED":  "END_FORCE",
+}
+
+//SetLoad forces a load actuator with the energy management (WHO 18) commands, that address it as 7N#0: FORCE_ON,
+//with its parameters (eg. FORCE_ON#1#100), or END_FORCE. LOAD_FORCED and END_FORCED are accepted too.
+func (h *Home) SetLoad(load Where, state string) error {
+	desc, params := splitParams(state)
+	if action, ok := loadActions[strings.ToUpper(desc)]; ok {
+		state = withParams(action, params)
+	}
+	what, err := whoEnergy().WhatFromDesc(state)
+	if err != nil || what.Desc == "RESET_TOTALIZER" {
+		return errors.Wrapf(ErrWhatNotFound, "unknown load state %s", state)
+	}
+	return h.Do(NewCommand(whoEnergy(), what, load))
+}
+
+//LoadStatus asks the state of a load actuator
+func (h *Home) LoadStatus(load Where) (LoadStatus, error) {
+	answers, err := h.Ask(NewRequest(whoLoad(), What{}, load))
+	if err != nil {
+		return LoadStatus{}, errors.Wrapf(err, "cannot get status of load %s", load.Desc)
+	}
+	for _, a := range answers {
+		if s, err := NewLoadStatus(a); err == nil {
+			return s, nil
+		}
+	}
+	return LoadStatus{}, errors.Wrapf(ErrNoData, "no status from load %s", load.Desc)
+}
@@ -0,0 +1,89 @@
+package gohome_test
+
+import (
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestEnergyReading(t *testing.T) {
+	plant := loadTestPlant(t)
+	readings := map[string]gohome.EnergyReading{
+		"*#18*51*113*1250##":  gohome.EnergyReading{Desc: "ACTIVE_POWER", Value: 1250, Where: gohome.Where{Desc: "meter:house"}},
+		"*#18*52*54*8300##":   gohome.EnergyReading{Desc: "CURRENT_DAY_ENERGY", Value: 8300, Where: gohome.Where{Desc: "meter:heatpump"}},
+		"*#18*51*51*123456##": gohome.EnergyReading{Desc: "TOTAL_ENERGY", Value: 123456, Where: gohome.Where{Desc: "meter:house"}},
+	}
+	for f, exp := range readings {
+		r, err := gohome.NewEnergyReading(plant.ParseFrame(f))
+		if err != nil {
+			t.Errorf("energy reading not decoded from %s: %v", f, err)
+			continue
+		}
+		if r.Desc != exp.Desc || r.Value != exp.Value || r.Where.Desc != exp.Where.Desc {
+			t.Errorf("wrong energy reading from %s: %+v", f, r)
+		}
+	}
+	r, _ := gohome.NewEnergyReading(plant.ParseFrame("*#18*51*113*1250##"))
+	if !r.IsPower() || r.String() != "meter:house ACTIVE_POWER: 1250 W" {
+		t.Errorf("wrong power reading: %s", r)
+	}
+	r, _ = gohome.NewEnergyReading(plant.ParseFrame("*#18*51*51*123456##"))
+	if r.IsPower() || r.String() != "meter:house TOTAL_ENERGY: 123.456 kWh" {
+		t.Errorf("wrong energy reading: %s", r)
+	}
+	if r, err := gohome.NewEnergyReading(plant.ParseFrame("*#18*51*113##")); err == nil {
+		t.Errorf("dimension request should not be an energy reading: %+v", r)
+	}
+}
+
+func TestEnergyWhere(t *testing.T) {
+	plant := loadTestPlant(t)
+	meter, err := plant.WhereFromDesc("meter:house")
+	if err != nil {
+		t.Errorf("Where not found: %v", err)
+	}
+	msg := gohome.NewDimensionWrite(gohome.NewWho("ENERGY"), meter, gohome.AUTOMATIC_POWER_UPDATE, "255")
+	if msg.Frame() != "*#18*51*#1200#1*255##" {
+		t.Errorf("wrong power update frame: %s", msg.Frame())
+	}
+	load, err := plant.WhereFromDesc("load:oven")
+	if err != nil {
+		t.Errorf("Where not found: %v", err)
+	}
+	who := gohome.NewWho("LOAD_CONTROL")
+	what, err := who.WhatFromDesc("LOAD_DISABLED")
+	if err != nil {
+		t.Errorf("What not found: %v", err)
+	}
+	if frame := gohome.NewCommand(who, what, load).Frame(); frame != "*3*0*71#0##" {
+		t.Errorf("wrong load frame: %s", frame)
+	}
+	if msg := plant.ParseFrame("*3*0*71#0##"); msg.Where.Desc != "load:oven" {
+		t.Errorf("wrong load decoded: %s", msg.Where.Desc)
+	}
+}
+
+func TestSetLoad(t *testing.T) {
+	plant := loadTestPlant(t)
+	load, err := plant.WhereFromDesc("load:oven")
+	if err != nil {
+		t.Fatalf("Where not found: %v", err)
+	}
+	frames := map[string]string{
+		"FORCE_ON#1#100": "*18*73#1#100*71#0##",
+		"END_FORCED":     "*18*74*71#0##",
+	}
+	for state, exp := range frames {
+		address, received := fakeGateway(t, "25280520")
+		plant.Address, plant.Password = address, "12345"
+		if err := gohome.NewHome(plant).SetLoad(load, state); err != nil {
+			t.Errorf("load not set to %s: %v", state, err)
+		}
+		if session, cmd := <-received, <-received; session != "*99*0##" || cmd != exp {
+			t.Errorf("wrong frames for %s: %s %s", state, session, cmd)
+		}
+	}
+	if err := gohome.NewHome(plant).SetLoad(load, "LOAD_DISABLED"); err == nil {
+		t.Errorf("load actuators cannot be disabled by energy management")
+	}
+}
@@ -0,0 +1,90 @@
+package gohome
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+//ErrFloorNotFound is returned when the desired floor is not found in the conf file
+var ErrFloorNotFound = errors.New("floor not found")
+
+//floorSeparator separates the floor from the ambient in a WHERE description: <floor>/<ambient>[.<device>]
+const floorSeparator = "/"
+
+//Floor is a level of the building (0 ground, negative for basements) with its ambients. When Group is set,
+//the floor is addressed with a single frame to that group instead of one frame per ambient.
+type Floor struct {
+	Level    int      `json:"level"`
+	Ambients []string `json:"ambients"`
+	Group    string   `json:"group,omitempty"`
+}
+
+//contains returns true if the ambient is on the floor
+func (f Floor) contains(ambient string) bool {
+	for _, a := range f.Ambients {
+		if a == ambient {
+			return true
+		}
+	}
+	return false
+}
+
+//floorWhere returns the where of a floor: its group if any, otherwise a where without code expanded by Expand
+func (p *Plant) floorWhere(name string, params []string) (Where, error) {
+	floor, ok := p.Floors[name]
+	if !ok {
+		return Where{}, ErrFloorNotFound
+	}
+	if floor.Group == "" {
+		return Where{Desc: name, Params: params}, nil
+	}
+	code, err := p.groupCode(floor.Group)
+	if err != nil {
+		return Where{}, errors.Wrapf(err, "group %s of floor %s", floor.Group, name)
+	}
+	return Where{Code: code, Desc: name, Params: params}, nil
+}
+
+//FloorNames returns the floors of the building from the lowest one
+func (p *Plant) FloorNames() []string {
+	names := make([]string, 0, len(p.Floors))
+	for n := range p.Floors {
+		names = append(names, n)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return p.Floors[names[i]].Level < p.Floors[names[j]].Level
+	})
+	return names
+}
+
+//Expand returns the wheres a where stands for when addressed to the WHO: the ambients of a floor without group,
+//the where itself otherwise. A floor is refused for the WHOs that do not accept ambients or groups.
+func (p *Plant) Expand(who *Who, where Where) ([]Where, error) {
+	floor, ok := p.Floors[where.Desc]
+	if !ok {
+		return []Where{where}, nil
+	}
+	form := "ambient"
+	if floor.Group != "" {
+		form = "group"
+	}
+	if who == nil {
+		return nil, errors.Wrapf(ErrWhoNotFound, "floor %s without WHO", where.Desc)
+	}
+	if !who.accepts(form) {
+		return nil, errors.Wrapf(ErrInvalidAddress, "floor %s is not a where of %s", where.Desc, who.Desc)
+	}
+	if where.Code != "" {
+		return []Where{where}, nil
+	}
+	wheres := make([]Where, 0, len(floor.Ambients))
+	for _, a := range floor.Ambients {
+		w, err := p.WhereFromDesc(withParams(a, where.Params))
+		if err != nil {
+			return nil, errors.Wrapf(err, "ambient %s of floor %s", a, where.Desc)
+		}
+		wheres = append(wheres, w)
+	}
+	return wheres, nil
+}
@@ -0,0 +1,86 @@
+package gohome_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestFloorWhere(t *testing.T) {
+	plant := loadTestPlant(t)
+	wheres := map[string]string{
+		"ground":               "#1",
+		"upstairs":             "",
+		"upstairs/studio":      "100#4#01",
+		"upstairs/studio.desk": "1005#4#01",
+		"basement/garage.door": "0003",
+	}
+	for desc, exp := range wheres {
+		where, err := plant.WhereFromDesc(desc)
+		if err != nil {
+			t.Errorf("Where %s not found: %v", desc, err)
+			continue
+		}
+		if where.Field().String() != exp || where.Desc != desc {
+			t.Errorf("wrong where for %s: %v", desc, where)
+		}
+	}
+	for _, desc := range []string{"ground/studio", "attic/studio", "upstairs/studio.lamp"} {
+		if where, err := plant.WhereFromDesc(desc); err == nil {
+			t.Errorf("%s should not be found: %v", desc, where)
+		}
+	}
+}
+
+func TestFloorExpand(t *testing.T) {
+	plant := loadTestPlant(t)
+	expected := map[string][]string{
+		"ground":          {"#1"},
+		"upstairs":        {"100#4#01"},
+		"basement":        {"00"},
+		"living.sofa":     {"21"},
+		"upstairs/studio": {"100#4#01"},
+	}
+	for desc, exp := range expected {
+		where, err := plant.WhereFromDesc(desc)
+		if err != nil {
+			t.Errorf("Where %s not found: %v", desc, err)
+			continue
+		}
+		wheres, err := plant.Expand(gohome.NewWho("LIGHT"), where)
+		if err != nil {
+			t.Errorf("Where %s not expanded: %v", desc, err)
+			continue
+		}
+		codes := make([]string, len(wheres))
+		for i, w := range wheres {
+			codes[i] = w.Field().String()
+		}
+		if !reflect.DeepEqual(codes, exp) {
+			t.Errorf("wrong expansion of %s: %v", desc, codes)
+		}
+	}
+	for _, desc := range []string{"ground", "upstairs"} {
+		where, _ := plant.WhereFromDesc(desc)
+		if wheres, err := plant.Expand(gohome.NewWho("THERMOREGULATION"), where); errors.Cause(err) != gohome.ErrInvalidAddress {
+			t.Errorf("floor %s should not be a thermoregulation where: %v (err: %v)", desc, wheres, err)
+		}
+	}
+	if floors := plant.FloorNames(); !reflect.DeepEqual(floors, []string{"basement", "ground", "upstairs"}) {
+		t.Errorf("wrong floors order: %v", floors)
+	}
+}
+
+func TestFloorAsk(t *testing.T) {
+	h := gohome.NewHome(loadTestPlant(t))
+	where, err := h.Current().WhereFromDesc("upstairs")
+	if err != nil {
+		t.Fatalf("Where upstairs not found: %v", err)
+	}
+	if _, err := h.Ask(gohome.NewRequest(gohome.NewWho("THERMOREGULATION"), gohome.What{}, where)); errors.Cause(err) != gohome.ErrInvalidAddress {
+		t.Errorf("floor should not be asked to the thermoregulation: %v", err)
+	}
+}
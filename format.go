@@ -0,0 +1,282 @@
+package gohome
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+//ErrUnknownFormat is returned when a plant configuration format is not supported
+var ErrUnknownFormat = errors.New("unknown configuration format")
+
+//Formats of the plant configuration, they share the schema of the JSON one
+const FORMAT_JSON = "json"
+const FORMAT_YAML = "yaml"
+const FORMAT_TOML = "toml"
+
+//formatExtensions are the file extensions of each format
+var formatExtensions = map[string]string{
+	".json": FORMAT_JSON,
+	".yaml": FORMAT_YAML,
+	".yml":  FORMAT_YAML,
+	".toml": FORMAT_TOML,
+}
+
+//tomlKey matches a TOML key/value line, YAML uses ':' instead of '='
+var tomlKey = regexp.MustCompile(`^[A-Za-z0-9_"'.-]+\s*=`)
+
+//FormatFromName returns the format of a configuration file from its extension
+func FormatFromName(name string) (string, error) {
+	format, ok := formatExtensions[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		return "", errors.Wrapf(ErrUnknownFormat, "file %s", name)
+	}
+	return format, nil
+}
+
+//sniffFormat guesses the format of a configuration from the first line that is not empty or a comment. A
+//configuration starting with '{' is JSON only if valid, otherwise it is a YAML flow mapping.
+func sniffFormat(data []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "{") && json.Valid(bytes.TrimPrefix(data, []byte("\ufeff"))):
+			return FORMAT_JSON
+		case strings.HasPrefix(line, "[") || tomlKey.MatchString(line):
+			return FORMAT_TOML
+		}
+		return FORMAT_YAML
+	}
+	return FORMAT_JSON
+}
+
+//plantJSON reads a configuration in any format and returns it as JSON. The format is given by the extension
+//when config is a file, otherwise by the content.
+func plantJSON(config io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read configuration")
+	}
+	format := sniffFormat(data)
+	if f, ok := config.(interface{ Name() string }); ok {
+		if byName, err := FormatFromName(f.Name()); err == nil {
+			format = byName
+		}
+	}
+	var tree interface{}
+	switch format {
+	case FORMAT_JSON:
+		return data, nil
+	case FORMAT_YAML:
+		var doc yaml.Node
+		if err = yaml.Unmarshal(data, &doc); err == nil {
+			tree, err = yamlTree(&doc)
+		}
+	case FORMAT_TOML:
+		err = toml.Unmarshal(data, &tree)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot decode %s configuration", format)
+	}
+	return json.Marshal(typedTree(tree, reflect.TypeOf(plantSchema{})))
+}
+
+//plantSchema is a configuration with a single plant or with several ones, whose types are given to the YAML and
+//TOML values
+type plantSchema struct {
+	Plant
+	Default string           `json:"default"`
+	Plants  map[string]Plant `json:"plants"`
+}
+
+//yamlScalar is a decoded YAML scalar with its text, that is used when a string is expected: 01 stays 01
+type yamlScalar struct {
+	text  string
+	value interface{}
+}
+
+//yamlTree returns the tree of a YAML node, the keys of the mappings are always strings
+func yamlTree(n *yaml.Node) (interface{}, error) {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return yamlTree(n.Content[0])
+	case yaml.AliasNode:
+		return yamlTree(n.Alias)
+	case yaml.SequenceNode:
+		seq := make([]interface{}, len(n.Content))
+		for i, c := range n.Content {
+			e, err := yamlTree(c)
+			if err != nil {
+				return nil, err
+			}
+			seq[i] = e
+		}
+		return seq, nil
+	case yaml.MappingNode:
+		m := map[string]interface{}{}
+		merged := []interface{}{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			e, err := yamlTree(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if n.Content[i].Tag == "!!merge" {
+				merged = append(merged, e)
+				continue
+			}
+			m[n.Content[i].Value] = e
+		}
+		for _, e := range merged {
+			maps, ok := e.([]interface{})
+			if !ok {
+				maps = []interface{}{e}
+			}
+			for _, mm := range maps {
+				mm, _ := mm.(map[string]interface{})
+				for k, v := range mm {
+					if _, ok := m[k]; !ok {
+						m[k] = v
+					}
+				}
+			}
+		}
+		return m, nil
+	}
+	var value interface{}
+	if err := n.Decode(&value); err != nil {
+		return nil, err
+	}
+	return yamlScalar{text: n.Value, value: value}, nil
+}
+
+//typedTree converts the scalars of the tree to strings where the type t has a string, as YAML and TOML read
+//address: 3 or password: 12345 as numbers
+func typedTree(tree interface{}, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	isString := t != nil && t.Kind() == reflect.String
+	switch v := tree.(type) {
+	case yamlScalar:
+		if isString && v.value != nil {
+			return v.text
+		}
+		return typedTree(v.value, t)
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = typedTree(e, fieldType(t, k))
+		}
+	case []interface{}:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for i, e := range v {
+			v[i] = typedTree(e, elem)
+		}
+	case int64, float64, bool:
+		if isString {
+			return fmt.Sprint(v)
+		}
+	}
+	return tree
+}
+
+//fieldType returns the type of the value at key in a map or in a struct, matched by JSON name as json.Unmarshal does
+func fieldType(t reflect.Type, key string) reflect.Type {
+	if t == nil {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem()
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.Anonymous {
+				if ft := fieldType(f.Type, key); ft != nil {
+					return ft
+				}
+				continue
+			}
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "" {
+				name = f.Name
+			}
+			if strings.EqualFold(name, key) {
+				return f.Type
+			}
+		}
+	}
+	return nil
+}
+
+//ExportPlantAs writes the current plant configuration to the given file in the format, in the current version
+func (p *Plant) ExportPlantAs(f io.Writer, format string) error {
+	p.migrate()
+	if format == FORMAT_JSON {
+		encoder := json.NewEncoder(f)
+		return encoder.Encode(p)
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return errors.Wrapf(err, "cannot encode plant")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return errors.Wrapf(err, "cannot encode plant")
+	}
+	tree = plainNumbers(tree)
+	switch format {
+	case FORMAT_YAML:
+		encoder := yaml.NewEncoder(f)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(tree); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case FORMAT_TOML:
+		return toml.NewEncoder(f).Encode(tree)
+	}
+	return errors.Wrapf(ErrUnknownFormat, "format %s", format)
+}
+
+//plainNumbers replaces the JSON numbers of a decoded tree with integers or floats, as YAML and TOML write them
+func plainNumbers(tree interface{}) interface{} {
+	switch v := tree.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = plainNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = plainNumbers(e)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return tree
+}
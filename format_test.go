@@ -0,0 +1,137 @@
+package gohome_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestPlantFormats(t *testing.T) {
+	expected := loadTestPlant(t)
+	for _, file := range []string{"testdata/casa.yaml", "testdata/casa.toml"} {
+		config, err := os.Open(file)
+		if err != nil {
+			t.Fatalf("Cannot open %s: %v", file, err)
+		}
+		plant, err := gohome.NewStrictPlant(config)
+		config.Close()
+		if err != nil {
+			t.Errorf("Cannot load %s: %v", file, err)
+			continue
+		}
+		if !reflect.DeepEqual(plant, expected) {
+			t.Errorf("%s differs from casa.json: %+v", file, plant)
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Cannot read %s: %v", file, err)
+		}
+		sniffed, err := gohome.NewPlant(bytes.NewReader(data))
+		if err != nil || !reflect.DeepEqual(sniffed, expected) {
+			t.Errorf("%s not recognized from its content: %v", file, err)
+		}
+	}
+}
+
+func TestPlantFormatsNumbers(t *testing.T) {
+	for _, file := range []string{"testdata/numbers.yaml", "testdata/numbers.toml"} {
+		config, err := os.Open(file)
+		if err != nil {
+			t.Fatalf("Cannot open %s: %v", file, err)
+		}
+		plant, err := gohome.NewStrictPlant(config)
+		config.Close()
+		if err != nil {
+			t.Errorf("Cannot load %s: %v", file, err)
+			continue
+		}
+		if plant.Password != "12345" || plant.Ambients["cellar"].Interface != "01" {
+			t.Errorf("wrong numbers as strings in %s: %+v", file, plant)
+		}
+		if door, _ := plant.Device("garage", "door"); door.Address != "3" {
+			t.Errorf("wrong device address in %s: %+v", file, door)
+		}
+		if fan, _ := plant.Device("cellar", "fan"); fan.Who != "1" || fan.Address != "2" {
+			t.Errorf("wrong device WHO in %s: %+v", file, fan)
+		}
+		if where, err := plant.WhereFromDesc("audio:hall.1"); err != nil || where.Code != "12" {
+			t.Errorf("wrong speaker with numeric name in %s: %v (err: %v)", file, where, err)
+		}
+	}
+}
+
+func TestPlantFlowYAML(t *testing.T) {
+	config := `{address: 192.168.0.35:20000, ambients: {kitchen: {num: 1, lights: {main: 2}}}}`
+	plant, err := gohome.NewStrictPlant(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("cannot load YAML flow mapping: %v", err)
+	}
+	if where, err := plant.WhereFromDesc("kitchen.main"); err != nil || where.Code != "12" {
+		t.Errorf("wrong where from YAML flow mapping: %v %v", where, err)
+	}
+}
+
+func TestExportPlantFormats(t *testing.T) {
+	plant := loadTestPlant(t)
+	dir, err := ioutil.TempDir("", "gohome")
+	if err != nil {
+		t.Fatalf("cannot create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"gohome.json", "gohome.yaml", "gohome.yml", "gohome.toml"} {
+		path := filepath.Join(dir, name)
+		out, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("cannot create %s: %v", path, err)
+		}
+		err = plant.ExportPlant(out)
+		out.Close()
+		if err != nil {
+			t.Errorf("cannot export %s: %v", name, err)
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("cannot read %s: %v", path, err)
+		}
+		if format, _ := gohome.FormatFromName(name); format != gohome.FORMAT_JSON && strings.HasPrefix(string(data), "{") {
+			t.Errorf("%s exported as JSON", name)
+		}
+		read, err := gohome.NewStrictPlant(bytes.NewReader(data))
+		if err != nil || !reflect.DeepEqual(read, plant) {
+			t.Errorf("%s changed by export: %v", name, err)
+		}
+	}
+	if err := plant.ExportPlantAs(ioutil.Discard, "xml"); err == nil {
+		t.Errorf("xml should not be supported")
+	}
+}
+
+func TestHomesFormats(t *testing.T) {
+	config := `
+# two plants
+default: house
+plants:
+  house:
+    address: 192.168.0.35:20000
+    ambients:
+      kitchen: {num: 1, lights: {main: 2}}
+  studio:
+    address: 192.168.1.35:20000
+    password: 12345
+`
+	homes, err := gohome.NewStrictHomes(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("cannot load YAML homes: %v", err)
+	}
+	h, where, err := homes.Target("kitchen.main")
+	if err != nil || h.Current().Address != "192.168.0.35:20000" || where.Code != "12" {
+		t.Errorf("wrong target in YAML homes: %v %v", where, err)
+	}
+}
@@ -0,0 +1,276 @@
+package gohome
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrFrameSyntax is returned when a string is not a well formed OpenWebNet frame
+var ErrFrameSyntax = errors.New("OWN frame syntax error")
+
+//Field is a frame field made of a code and an optional list of #-separated parameters (eg. WHAT#P1#P2)
+type Field struct {
+	Code   string
+	Params []string
+}
+
+//String returns the field as it is written in a frame
+func (f Field) String() string {
+	if len(f.Params) == 0 {
+		return f.Code
+	}
+	return f.Code + "#" + strings.Join(f.Params, "#")
+}
+
+//IsEmpty returns true if the field has no code and no parameters
+func (f Field) IsEmpty() bool {
+	return f.Code == "" && len(f.Params) == 0
+}
+
+//Frame is the syntax tree of a single OpenWebNet frame
+type Frame struct {
+	Raw       string
+	Kind      string
+	Who       string
+	What      Field
+	Where     Field
+	Dimension Field
+	Values    []string
+}
+
+type tokenKind int
+
+const (
+	tokStar tokenKind = iota
+	tokHash
+	tokNumber
+	tokEnd
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+//lex splits a frame in tokens. The closing ## is returned as a single tokEnd.
+func lex(raw string) ([]token, error) {
+	if !strings.HasPrefix(raw, "*") || !strings.HasSuffix(raw, "##") || len(raw) < 4 {
+		return nil, errors.Wrapf(ErrFrameSyntax, "frame must start with '*' and end with '##': '%s'", raw)
+	}
+	body := raw[:len(raw)-2]
+	tokens := make([]token, 0, len(body))
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '*':
+			tokens = append(tokens, token{kind: tokStar, text: "*", pos: i})
+		case c == '#':
+			tokens = append(tokens, token{kind: tokHash, text: "#", pos: i})
+		case c >= '0' && c <= '9':
+			start := i
+			for i+1 < len(body) && body[i+1] >= '0' && body[i+1] <= '9' {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: body[start : i+1], pos: start})
+		default:
+			return nil, errors.Wrapf(ErrFrameSyntax, "unexpected character '%c' at %d in '%s'", c, i, raw)
+		}
+	}
+	tokens = append(tokens, token{kind: tokEnd, text: "##", pos: len(body)})
+	return tokens, nil
+}
+
+type frameParser struct {
+	raw    string
+	tokens []token
+	pos    int
+}
+
+func (p *frameParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *frameParser) accept(kind tokenKind) bool {
+	if p.peek().kind != kind {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *frameParser) expect(kind tokenKind) (token, error) {
+	t := p.peek()
+	if t.kind != kind {
+		return t, errors.Wrapf(ErrFrameSyntax, "unexpected '%s' at %d in '%s'", t.text, t.pos, p.raw)
+	}
+	p.pos++
+	return t, nil
+}
+
+//field parses CODE[#P1[#P2..]]. A leading # is kept in the code when allowed (eg. group WHERE #5).
+func (p *frameParser) field(leadingHash bool, allowEmpty bool) (Field, error) {
+	f := Field{}
+	next := p.peek().kind
+	if allowEmpty && (next == tokStar || next == tokEnd) {
+		return f, nil
+	}
+	if leadingHash && p.accept(tokHash) {
+		f.Code = "#"
+	}
+	code, err := p.expect(tokNumber)
+	if err != nil {
+		return f, err
+	}
+	f.Code += code.text
+	for p.accept(tokHash) {
+		par, err := p.expect(tokNumber)
+		if err != nil {
+			return f, err
+		}
+		f.Params = append(f.Params, par.text)
+	}
+	return f, nil
+}
+
+//values parses one or more *VAL fields until the end of the frame
+func (p *frameParser) values() ([]string, error) {
+	vals := make([]string, 0, 4)
+	for !p.accept(tokEnd) {
+		if _, err := p.expect(tokStar); err != nil {
+			return nil, err
+		}
+		v, err := p.expect(tokNumber)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v.text)
+	}
+	if len(vals) == 0 {
+		return nil, errors.Wrapf(ErrFrameSyntax, "missing values in '%s'", p.raw)
+	}
+	return vals, nil
+}
+
+//parse implements the OpenWebNet grammar:
+//
+//	*#*1##                               ACK
+//	*#*0##                               NACK
+//	*99*SESSION##                        session opener
+//	*WHO*WHAT*WHERE##                    command
+//	*#WHO*WHERE##                        status request
+//	*#WHO*WHERE*DIM##                    dimension request
+//	*#WHO*WHERE*#DIM[*VAL1*..*VALn]##    dimension write (values are optional)
+//	*#WHO*WHERE*DIM*VAL1*..*VALn##       dimension response
+func (p *frameParser) parse() (*Frame, error) {
+	f := &Frame{Raw: p.raw}
+	if _, err := p.expect(tokStar); err != nil {
+		return nil, err
+	}
+	if !p.accept(tokHash) {
+		who, err := p.expect(tokNumber)
+		if err != nil {
+			return nil, err
+		}
+		f.Who = who.text
+		if _, err := p.expect(tokStar); err != nil {
+			return nil, err
+		}
+		if f.What, err = p.field(false, false); err != nil {
+			return nil, err
+		}
+		if p.accept(tokEnd) {
+			if f.Who != "99" {
+				return nil, errors.Wrapf(ErrFrameSyntax, "missing WHERE in '%s'", p.raw)
+			}
+			f.Kind = SESSION
+			return f, nil
+		}
+		if _, err := p.expect(tokStar); err != nil {
+			return nil, err
+		}
+		if f.Where, err = p.field(true, false); err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokEnd); err != nil {
+			return nil, err
+		}
+		f.Kind = COMMAND
+		return f, nil
+	}
+	if p.accept(tokStar) {
+		ack, err := p.expect(tokNumber)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokEnd); err != nil {
+			return nil, err
+		}
+		switch ack.text {
+		case "1":
+			f.Kind = ACKNOWLEDGE
+		case "0":
+			f.Kind = NOTACKNOWLEDGE
+		default:
+			return nil, errors.Wrapf(ErrFrameSyntax, "unknown acknowledge '%s'", p.raw)
+		}
+		return f, nil
+	}
+	who, err := p.expect(tokNumber)
+	if err != nil {
+		return nil, err
+	}
+	f.Who = who.text
+	if _, err := p.expect(tokStar); err != nil {
+		return nil, err
+	}
+	if f.Where, err = p.field(true, true); err != nil {
+		return nil, err
+	}
+	if p.accept(tokEnd) {
+		if f.Where.IsEmpty() {
+			return nil, errors.Wrapf(ErrFrameSyntax, "missing WHERE in '%s'", p.raw)
+		}
+		f.Kind = REQUEST
+		return f, nil
+	}
+	if _, err := p.expect(tokStar); err != nil {
+		return nil, err
+	}
+	if p.accept(tokHash) {
+		if f.Dimension, err = p.field(false, false); err != nil {
+			return nil, err
+		}
+		f.Kind = DIMENSIONSET
+		if p.accept(tokEnd) {
+			return f, nil
+		}
+		if f.Values, err = p.values(); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+	if f.Dimension, err = p.field(false, false); err != nil {
+		return nil, err
+	}
+	if p.accept(tokEnd) {
+		f.Kind = DIMENSIONGET
+		return f, nil
+	}
+	if f.Values, err = p.values(); err != nil {
+		return nil, err
+	}
+	f.Kind = DIMENSIONRESPONSE
+	return f, nil
+}
+
+//NewFrame parses a raw OpenWebNet frame and returns its syntax tree
+func NewFrame(raw string) (*Frame, error) {
+	tokens, err := lex(raw)
+	if err != nil {
+		return nil, err
+	}
+	p := frameParser{raw: raw, tokens: tokens}
+	return p.parse()
+}
@@ -0,0 +1,101 @@
+package gohome_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestNewFrameKinds(t *testing.T) {
+	frames := map[string]string{
+		"*#*1##":                  gohome.ACKNOWLEDGE,
+		"*#*0##":                  gohome.NOTACKNOWLEDGE,
+		"*99*0##":                 gohome.SESSION,
+		"*99*9##":                 gohome.SESSION,
+		"*1*1*23##":               gohome.COMMAND,
+		"*1*1#50*0#3##":           gohome.COMMAND,
+		"*2*1000#1*0512#4#01##":   gohome.COMMAND,
+		"*1*0*#5##":               gohome.COMMAND,
+		"*#1*12##":                gohome.REQUEST,
+		"*#1001*35##":             gohome.REQUEST,
+		"*#1*#3##":                gohome.REQUEST,
+		"*#1*12*1##":              gohome.DIMENSIONGET,
+		"*#13**16##":              gohome.DIMENSIONGET,
+		"*#1*12*#1*150*0##":       gohome.DIMENSIONSET,
+		"*#13**#0*12*30*00*001##": gohome.DIMENSIONSET,
+		"*#1*12*#3##":             gohome.DIMENSIONSET,
+		"*#4*1*0*0210##":          gohome.DIMENSIONRESPONSE,
+		"*#18*51*113*1250##":      gohome.DIMENSIONRESPONSE,
+	}
+	for raw, kind := range frames {
+		f, err := gohome.NewFrame(raw)
+		if err != nil {
+			t.Errorf("frame '%s' not parsed: %v", raw, err)
+			continue
+		}
+		if f.Kind != kind {
+			t.Errorf("frame '%s' parsed as %s instead of %s", raw, f.Kind, kind)
+		}
+	}
+}
+
+func TestNewFrameInvalid(t *testing.T) {
+	frames := []string{
+		"",
+		"*",
+		"*##",
+		"*#*##",
+		"*#*2##",
+		"*1*9##",
+		"*1**2##",
+		"*1*1*##",
+		"*1*6*d##",
+		"*#1*##",
+		"*#1*1*##",
+		"*#1*1*#1*##",
+		"*1*1#*12##",
+		"*1*1*12##*1*1*11##",
+		"1*1*12##",
+		"*1*1*12#",
+	}
+	for _, raw := range frames {
+		if f, err := gohome.NewFrame(raw); err == nil {
+			t.Errorf("frame '%s' should be invalid, parsed as %+v", raw, f)
+		}
+	}
+}
+
+func TestNewFrameFields(t *testing.T) {
+	f, err := gohome.NewFrame("*1*1#50*0512#4#01##")
+	if err != nil {
+		t.Fatalf("frame not parsed: %v", err)
+	}
+	if f.Who != "1" || f.What.Code != "1" || strings.Join(f.What.Params, ",") != "50" {
+		t.Errorf("wrong WHO/WHAT: %+v", f)
+	}
+	if f.Where.Code != "0512" || strings.Join(f.Where.Params, ",") != "4,01" || f.Where.String() != "0512#4#01" {
+		t.Errorf("wrong WHERE: %+v", f.Where)
+	}
+	f, err = gohome.NewFrame("*#4*1*0*0210##")
+	if err != nil {
+		t.Fatalf("frame not parsed: %v", err)
+	}
+	if f.Who != "4" || f.Where.Code != "1" || f.Dimension.Code != "0" || len(f.Values) != 1 || f.Values[0] != "0210" {
+		t.Errorf("wrong dimension response: %+v", f)
+	}
+	f, err = gohome.NewFrame("*#1*#3##")
+	if err != nil {
+		t.Fatalf("frame not parsed: %v", err)
+	}
+	if f.Where.Code != "#3" {
+		t.Errorf("wrong group WHERE: %+v", f.Where)
+	}
+	f, err = gohome.NewFrame("*#13**#0*12*30*00*001##")
+	if err != nil {
+		t.Fatalf("frame not parsed: %v", err)
+	}
+	if !f.Where.IsEmpty() || f.Dimension.Code != "0" || strings.Join(f.Values, ",") != "12,30,00,001" {
+		t.Errorf("wrong dimension write: %+v", f)
+	}
+}
@@ -0,0 +1,288 @@
+package gohome
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//ErrGatewayData is returned when the values of a gateway dimension cannot be decoded
+var ErrGatewayData = errors.New("wrong gateway data")
+
+//gatewayModels maps the MODEL dimension to the name of the device
+var gatewayModels = map[string]string{
+	"2":  "MHServer",
+	"4":  "MH200",
+	"6":  "F452",
+	"7":  "F452V",
+	"11": "MHServer2",
+	"12": "F453AV",
+	"13": "H4684",
+}
+
+//GatewayInfo collects the information the gateway reports about itself
+type GatewayInfo struct {
+	Time         time.Time
+	IP           string
+	Netmask      string
+	MAC          string
+	Model        string
+	Firmware     string
+	Kernel       string
+	Distribution string
+	Uptime       time.Duration
+}
+
+func atoiValues(values []Value, n int) ([]int, error) {
+	if len(values) < n {
+		return nil, errors.Wrapf(ErrGatewayData, "%d values instead of %d", len(values), n)
+	}
+	nums := make([]int, n)
+	for i := 0; i < n; i++ {
+		v, err := strconv.Atoi(string(values[i]))
+		if err != nil {
+			return nil, errors.Wrapf(ErrGatewayData, "value '%s' is not a number", values[i])
+		}
+		nums[i] = v
+	}
+	return nums, nil
+}
+
+//decodeTimezone converts the OWN timezone (sign digit + hours, eg. 001 = +1, 102 = -2) to a location
+func decodeTimezone(v Value) (*time.Location, error) {
+	t := string(v)
+	if len(t) != 3 || (t[0] != '0' && t[0] != '1') {
+		return nil, errors.Wrapf(ErrGatewayData, "wrong timezone '%s'", t)
+	}
+	hours, err := strconv.Atoi(t[1:])
+	if err != nil {
+		return nil, errors.Wrapf(ErrGatewayData, "wrong timezone '%s'", t)
+	}
+	if t[0] == '1' {
+		hours = -hours
+	}
+	return time.FixedZone(fmt.Sprintf("UTC%+d", hours), hours*3600), nil
+}
+
+//DecodeGatewayTime decodes the values of the DATE_TIME dimension: H*M*S*T*W*D*M*A
+func DecodeGatewayTime(values []Value) (time.Time, error) {
+	if len(values) < 8 {
+		return time.Time{}, errors.Wrapf(ErrGatewayData, "%d values instead of 8", len(values))
+	}
+	hms, err := atoiValues(values[0:3], 3)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := decodeTimezone(values[3])
+	if err != nil {
+		return time.Time{}, err
+	}
+	dmy, err := atoiValues(values[5:8], 3)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(dmy[2], time.Month(dmy[1]), dmy[0], hms[0], hms[1], hms[2], 0, loc), nil
+}
+
+//EncodeGatewayTime returns the values of the DATE_TIME dimension for the given time
+func EncodeGatewayTime(t time.Time) []Value {
+	_, offset := t.Zone()
+	sign := "0"
+	if offset < 0 {
+		sign, offset = "1", -offset
+	}
+	return []Value{
+		Value(fmt.Sprintf("%02d", t.Hour())),
+		Value(fmt.Sprintf("%02d", t.Minute())),
+		Value(fmt.Sprintf("%02d", t.Second())),
+		Value(fmt.Sprintf("%s%02d", sign, offset/3600)),
+		Value(fmt.Sprintf("%02d", int(t.Weekday()))),
+		Value(fmt.Sprintf("%02d", t.Day())),
+		Value(fmt.Sprintf("%02d", int(t.Month()))),
+		Value(fmt.Sprintf("%04d", t.Year())),
+	}
+}
+
+//DecodeGatewayIP decodes the values of the IP_ADDRESS and NETMASK dimensions
+func DecodeGatewayIP(values []Value) (string, error) {
+	nums, err := atoiValues(values, 4)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", nums[0], nums[1], nums[2], nums[3]), nil
+}
+
+//DecodeGatewayMAC decodes the values of the MAC_ADDRESS dimension, each byte is sent in decimal
+func DecodeGatewayMAC(values []Value) (string, error) {
+	nums, err := atoiValues(values, 6)
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, len(nums))
+	for i, n := range nums {
+		parts[i] = fmt.Sprintf("%02x", n)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+//DecodeGatewayModel decodes the value of the MODEL dimension
+func DecodeGatewayModel(values []Value) (string, error) {
+	if len(values) < 1 {
+		return "", errors.Wrapf(ErrGatewayData, "no model")
+	}
+	model, ok := gatewayModels[strings.TrimLeft(string(values[0]), "0")]
+	if !ok {
+		return fmt.Sprintf("model %s", values[0]), nil
+	}
+	return model, nil
+}
+
+//DecodeGatewayVersion decodes the values of the FIRMWARE_VERSION, KERNEL_VERSION and DISTRIBUTION_VERSION dimensions
+func DecodeGatewayVersion(values []Value) (string, error) {
+	nums, err := atoiValues(values, 3)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d.%d.%d", nums[0], nums[1], nums[2]), nil
+}
+
+//DecodeGatewayUptime decodes the values of the UPTIME dimension: D*H*M*S
+func DecodeGatewayUptime(values []Value) (time.Duration, error) {
+	nums, err := atoiValues(values, 4)
+	if err != nil {
+		return 0, err
+	}
+	d := time.Duration(nums[0])*24*time.Hour + time.Duration(nums[1])*time.Hour + time.Duration(nums[2])*time.Minute + time.Duration(nums[3])*time.Second
+	return d, nil
+}
+
+func (h *Home) askGateway(dim Dimension) ([]Value, error) {
+	answer, err := h.askDimension(whoGateway(), Where{}, dim)
+	if err != nil {
+		return nil, err
+	}
+	return answer.Values, nil
+}
+
+//GatewayTime reads date and time of the gateway
+func (h *Home) GatewayTime() (time.Time, error) {
+	values, err := h.askGateway("22")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return DecodeGatewayTime(values)
+}
+
+//GatewayIP reads the IP address of the gateway
+func (h *Home) GatewayIP() (string, error) {
+	values, err := h.askGateway("10")
+	if err != nil {
+		return "", err
+	}
+	return DecodeGatewayIP(values)
+}
+
+//GatewayNetmask reads the netmask of the gateway
+func (h *Home) GatewayNetmask() (string, error) {
+	values, err := h.askGateway("11")
+	if err != nil {
+		return "", err
+	}
+	return DecodeGatewayIP(values)
+}
+
+//GatewayMAC reads the MAC address of the gateway
+func (h *Home) GatewayMAC() (string, error) {
+	values, err := h.askGateway("12")
+	if err != nil {
+		return "", err
+	}
+	return DecodeGatewayMAC(values)
+}
+
+//GatewayModel reads the device model of the gateway
+func (h *Home) GatewayModel() (string, error) {
+	values, err := h.askGateway("15")
+	if err != nil {
+		return "", err
+	}
+	return DecodeGatewayModel(values)
+}
+
+//GatewayFirmware reads the firmware version of the gateway
+func (h *Home) GatewayFirmware() (string, error) {
+	values, err := h.askGateway("16")
+	if err != nil {
+		return "", err
+	}
+	return DecodeGatewayVersion(values)
+}
+
+//GatewayKernel reads the kernel version of the gateway
+func (h *Home) GatewayKernel() (string, error) {
+	values, err := h.askGateway("23")
+	if err != nil {
+		return "", err
+	}
+	return DecodeGatewayVersion(values)
+}
+
+//GatewayDistribution reads the distribution version of the gateway
+func (h *Home) GatewayDistribution() (string, error) {
+	values, err := h.askGateway("24")
+	if err != nil {
+		return "", err
+	}
+	return DecodeGatewayVersion(values)
+}
+
+//GatewayUptime reads for how long the gateway is running
+func (h *Home) GatewayUptime() (time.Duration, error) {
+	values, err := h.askGateway("19")
+	if err != nil {
+		return 0, err
+	}
+	return DecodeGatewayUptime(values)
+}
+
+//GatewayInfo reads all the information of the gateway, stopping at the first failure
+func (h *Home) GatewayInfo() (GatewayInfo, error) {
+	info := GatewayInfo{}
+	var err error
+	if info.Time, err = h.GatewayTime(); err != nil {
+		return info, err
+	}
+	if info.IP, err = h.GatewayIP(); err != nil {
+		return info, err
+	}
+	if info.Netmask, err = h.GatewayNetmask(); err != nil {
+		return info, err
+	}
+	if info.MAC, err = h.GatewayMAC(); err != nil {
+		return info, err
+	}
+	if info.Model, err = h.GatewayModel(); err != nil {
+		return info, err
+	}
+	if info.Firmware, err = h.GatewayFirmware(); err != nil {
+		return info, err
+	}
+	if info.Kernel, err = h.GatewayKernel(); err != nil {
+		return info, err
+	}
+	if info.Distribution, err = h.GatewayDistribution(); err != nil {
+		return info, err
+	}
+	if info.Uptime, err = h.GatewayUptime(); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+//SetGatewayTime sets date and time of the gateway
+func (h *Home) SetGatewayTime(t time.Time) error {
+	return h.Do(NewDimensionWrite(whoGateway(), Where{}, "22", EncodeGatewayTime(t)...))
+}
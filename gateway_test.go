@@ -0,0 +1,63 @@
+package gohome_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestGatewayDimensions(t *testing.T) {
+	plant := loadTestPlant(t)
+	msg := plant.ParseFrame("*#13**22*21*15*30*001*03*25*12*2019##")
+	if msg.Kind != gohome.DIMENSIONRESPONSE || msg.Who.Desc != "GATEWAY" {
+		t.Fatalf("gateway frame not decoded: %v", msg)
+	}
+	tm, err := gohome.DecodeGatewayTime(msg.Values)
+	if err != nil {
+		t.Errorf("gateway time not decoded: %v", err)
+	}
+	exp := time.Date(2019, time.December, 25, 21, 15, 30, 0, time.FixedZone("", 3600))
+	if !tm.Equal(exp) {
+		t.Errorf("wrong gateway time %v, expected was %v", tm, exp)
+	}
+	decoders := map[string]func([]gohome.Value) (string, error){
+		"*#13**10*192*168*0*35##":   gohome.DecodeGatewayIP,
+		"*#13**11*255*255*255*0##":  gohome.DecodeGatewayIP,
+		"*#13**12*0*3*80*0*56*42##": gohome.DecodeGatewayMAC,
+		"*#13**15*4##":              gohome.DecodeGatewayModel,
+		"*#13**16*3*0*15##":         gohome.DecodeGatewayVersion,
+	}
+	expected := map[string]string{
+		"*#13**10*192*168*0*35##":   "192.168.0.35",
+		"*#13**11*255*255*255*0##":  "255.255.255.0",
+		"*#13**12*0*3*80*0*56*42##": "00:03:50:00:38:2a",
+		"*#13**15*4##":              "MH200",
+		"*#13**16*3*0*15##":         "3.0.15",
+	}
+	for f, decode := range decoders {
+		v, err := decode(plant.ParseFrame(f).Values)
+		if err != nil || v != expected[f] {
+			t.Errorf("wrong gateway data from %s: %s (err: %v)", f, v, err)
+		}
+	}
+	up, err := gohome.DecodeGatewayUptime(plant.ParseFrame("*#13**19*2*3*4*5##").Values)
+	if err != nil || up != 51*time.Hour+4*time.Minute+5*time.Second {
+		t.Errorf("wrong gateway uptime: %v (err: %v)", up, err)
+	}
+	if _, err := gohome.DecodeGatewayIP([]gohome.Value{"192", "168"}); err == nil {
+		t.Errorf("short IP should not be decoded")
+	}
+}
+
+func TestSetGatewayTime(t *testing.T) {
+	tm := time.Date(2019, time.December, 25, 21, 15, 30, 0, time.FixedZone("", -7200))
+	msg := gohome.NewDimensionWrite(gohome.NewWho("GATEWAY"), gohome.Where{}, "22", gohome.EncodeGatewayTime(tm)...)
+	if msg.Frame() != "*#13**#22*21*15*30*102*03*25*12*2019##" {
+		t.Errorf("wrong gateway time frame: %s", msg.Frame())
+	}
+	back, err := gohome.DecodeGatewayTime(gohome.EncodeGatewayTime(tm))
+	if err != nil || !back.Equal(tm) {
+		t.Errorf("gateway time not encoded back: %v (err: %v)", back, err)
+	}
+}
@@ -1,12 +1,14 @@
 module github.com/savardiego/gohome
 
-go 1.12
+go 1.16
 
 require (
 	cloud.google.com/go v0.45.1
 	cloud.google.com/go/pubsub v1.0.1
+	github.com/BurntSushi/toml v1.2.1
 	github.com/pkg/errors v0.8.1
 	github.com/ramya-rao-a/go-outline v0.0.0-20181122025142-7182a932836a // indirect
 	golang.org/x/tools v0.0.0-20190917162342-3b4f30a44f3b // indirect
 	google.golang.org/api v0.9.0
+	gopkg.in/yaml.v3 v3.0.1
 )
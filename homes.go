@@ -0,0 +1,165 @@
+package gohome
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrPlantNotFound is returned when the desired plant is not in the configuration
+var ErrPlantNotFound = errors.New("plant not found")
+
+//ErrPlantNotSelected is returned when the configuration has several plants and none is chosen
+var ErrPlantNotSelected = errors.New("plant not selected")
+
+//ErrAmbiguousTarget is returned when a where is found in more than one plant
+var ErrAmbiguousTarget = errors.New("where found in more than one plant")
+
+//plantSeparator separates the plant from the where in a target: <plant>@<where>
+const plantSeparator = "@"
+
+//Homes are the plants of a configuration, each controlled through its own gateway. Default is the plant used
+//when none is chosen.
+type Homes struct {
+	Default string
+	homes   map[string]*Home
+}
+
+//NewHomes loads a configuration with several plants, {"default": <name>, "plants": {<name>: <plant>..}}, or
+//with a single plant, named after the plant. The problems of the plants are logged, NewStrictHomes refuses them.
+func NewHomes(config io.Reader) (*Homes, error) {
+	return newHomes(config, false)
+}
+
+//NewStrictHomes loads a configuration like NewHomes, but fails if any plant has problems
+func NewStrictHomes(config io.Reader) (*Homes, error) {
+	return newHomes(config, true)
+}
+
+func newHomes(config io.Reader, strict bool) (*Homes, error) {
+	if config == nil {
+		return nil, errors.New("Plant configuration is nil")
+	}
+	data, err := plantJSON(config)
+	if err != nil {
+		return nil, err
+	}
+	multi := struct {
+		Default string                     `json:"default"`
+		Plants  map[string]json.RawMessage `json:"plants"`
+	}{}
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return nil, err
+	}
+	paths := map[string]string{}
+	if multi.Plants == nil {
+		plant, err := decodePlant(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		multi.Default = plant.Name
+		if multi.Default == "" {
+			multi.Default = "home"
+		}
+		multi.Plants = map[string]json.RawMessage{multi.Default: data}
+		paths[multi.Default] = ""
+	}
+	homes := &Homes{Default: multi.Default, homes: map[string]*Home{}}
+	problems := Problems{}
+	for name, raw := range multi.Plants {
+		plant, err := decodePlant(bytes.NewReader(raw))
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot load plant %s", name)
+		}
+		prefix, ok := paths[name]
+		if !ok {
+			prefix = "plants." + name + "."
+		}
+		for _, pr := range plant.Validate() {
+			pr.Path = prefix + pr.Path
+			problems = append(problems, pr)
+		}
+		homes.homes[name] = NewHome(plant)
+	}
+	if homes.Default != "" && homes.homes[homes.Default] == nil {
+		problems = append(problems, Problem{Path: "default", Err: errors.Wrapf(ErrPlantNotFound, "plant %s", homes.Default)})
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Path < problems[j].Path
+	})
+	if strict && len(problems) > 0 {
+		return nil, errors.WithStack(problems)
+	}
+	for _, pr := range problems {
+		log.Printf("plant configuration problem at %v", pr)
+	}
+	return homes, nil
+}
+
+//Names returns the names of the plants
+func (hs *Homes) Names() []string {
+	names := make([]string, 0, len(hs.homes))
+	for n := range hs.homes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//Home returns the home of the plant with the given name, the default one or the only one when the name is empty
+func (hs *Homes) Home(name string) (*Home, error) {
+	if name == "" {
+		name = hs.Default
+	}
+	if name == "" && len(hs.homes) == 1 {
+		name = hs.Names()[0]
+	}
+	if name == "" {
+		return nil, errors.Wrapf(ErrPlantNotSelected, "choose one of %s", strings.Join(hs.Names(), ", "))
+	}
+	h, ok := hs.homes[name]
+	if !ok {
+		return nil, errors.Wrapf(ErrPlantNotFound, "plant %s", name)
+	}
+	return h, nil
+}
+
+//Target returns the home that controls the target and the where of the target in its plant. The target is
+//<plant>@<where>, or just <where> when it is found in a single plant or in the default one.
+func (hs *Homes) Target(target string) (*Home, Where, error) {
+	if i := strings.Index(target, plantSeparator); i > 0 {
+		h, err := hs.Home(target[:i])
+		if err != nil {
+			return nil, Where{}, err
+		}
+		where, err := h.Current().WhereFromDesc(target[i+1:])
+		return h, where, err
+	}
+	found := []string{}
+	var lastErr error
+	for _, n := range hs.Names() {
+		if _, err := hs.homes[n].Current().WhereFromDesc(target); err != nil {
+			lastErr = err
+			continue
+		}
+		found = append(found, n)
+	}
+	switch {
+	case len(found) == 0:
+		return nil, Where{}, lastErr
+	case len(found) > 1:
+		i := sort.SearchStrings(found, hs.Default)
+		if i == len(found) || found[i] != hs.Default {
+			return nil, Where{}, errors.Wrapf(ErrAmbiguousTarget, "%s is in %s", target, strings.Join(found, ", "))
+		}
+		found = []string{hs.Default}
+	}
+	h := hs.homes[found[0]]
+	where, err := h.Current().WhereFromDesc(target)
+	return h, where, err
+}
@@ -0,0 +1,159 @@
+package gohome_test
+
+import (
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/savardiego/gohome"
+)
+
+func loadTestHomes(t *testing.T) *gohome.Homes {
+	config, err := os.Open("testdata/homes.json")
+	if err != nil {
+		t.Fatalf("cannot open json file")
+	}
+	defer config.Close()
+	homes, err := gohome.NewStrictHomes(config)
+	if err != nil {
+		t.Fatalf("cannot load homes from config file: %v", err)
+	}
+	return homes
+}
+
+func TestHomes(t *testing.T) {
+	homes := loadTestHomes(t)
+	if names := strings.Join(homes.Names(), ","); names != "house,studio" {
+		t.Errorf("wrong plants: %s", names)
+	}
+	h, err := homes.Home("")
+	if err != nil || h.Current().Name != "house" {
+		t.Errorf("default plant should be house: %v", err)
+	}
+	h, err = homes.Home("studio")
+	if err != nil || h.Current().ServerAddress() != "192.168.1.35:20000" || h.Current().Password != "12345" {
+		t.Errorf("wrong studio plant: %v", err)
+	}
+	if _, err := homes.Home("attic"); errors.Cause(err) != gohome.ErrPlantNotFound {
+		t.Errorf("attic should not be found: %v", err)
+	}
+	config, err := os.Open("testdata/casa.json")
+	if err != nil {
+		t.Fatalf("cannot open json file")
+	}
+	defer config.Close()
+	single, err := gohome.NewHomes(config)
+	if err != nil {
+		t.Fatalf("cannot load single plant: %v", err)
+	}
+	if h, err := single.Home(""); err != nil || len(single.Names()) != 1 || h.Current().Ambients["kitchen"].Num != 1 {
+		t.Errorf("wrong single plant %v: %v", single.Names(), err)
+	}
+}
+
+func TestHomesTarget(t *testing.T) {
+	homes := loadTestHomes(t)
+	targets := map[string][2]string{
+		"studio@office.desk": {"studio", "11"},
+		"office.desk":        {"studio", "11"},
+		"kitchen.main":       {"house", "12"},
+		"kitchen":            {"house", "1"},
+		"studio@kitchen":     {"studio", "3"},
+	}
+	for target, exp := range targets {
+		h, where, err := homes.Target(target)
+		if err != nil {
+			t.Errorf("target %s not found: %v", target, err)
+			continue
+		}
+		if h.Current().Name != exp[0] || where.Code != exp[1] {
+			t.Errorf("wrong target %s: %s %v", target, h.Current().Name, where)
+		}
+	}
+	if _, _, err := homes.Target("attic"); err == nil {
+		t.Errorf("attic should not be found")
+	}
+	homes.Default = ""
+	if _, _, err := homes.Target("kitchen"); errors.Cause(err) != gohome.ErrAmbiguousTarget {
+		t.Errorf("kitchen should be ambiguous without default: %v", err)
+	}
+	if _, err := homes.Home(""); errors.Cause(err) != gohome.ErrPlantNotSelected {
+		t.Errorf("no plant should be selected: %v", err)
+	}
+}
+
+func TestStrictHomes(t *testing.T) {
+	config := `{"default": "garage", "plants": {"house": {"address": "192.168.0.35:20000"}, "studio": {"password": "secret"}}}`
+	_, err := gohome.NewStrictHomes(strings.NewReader(config))
+	problems, ok := errors.Cause(err).(gohome.Problems)
+	if !ok {
+		t.Fatalf("strict load should fail with the problems: %v", err)
+	}
+	paths := make([]string, len(problems))
+	for i, pr := range problems {
+		paths[i] = pr.Path
+	}
+	if strings.Join(paths, ",") != "default,plants.studio.address,plants.studio.password" {
+		t.Errorf("wrong problems: %v", problems)
+	}
+}
+
+//fakeGateway accepts a connection and plays the OPEN password authentication with the nonce 603356072
+func fakeGateway(t *testing.T, password string) (string, <-chan string) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("cannot listen: %v", err)
+	}
+	frames := make(chan string, 3)
+	go func() {
+		defer l.Close()
+		defer close(frames)
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		read := func() string {
+			frame := []byte{}
+			b := make([]byte, 1)
+			for !strings.HasSuffix(string(frame), "##") {
+				if _, err := conn.Read(b); err != nil {
+					return ""
+				}
+				frame = append(frame, b[0])
+			}
+			return string(frame)
+		}
+		conn.Write([]byte("*#*1##"))
+		frames <- read()
+		conn.Write([]byte("*#603356072##"))
+		if read() != "*#"+password+"##" {
+			conn.Write([]byte("*#*0##"))
+			return
+		}
+		conn.Write([]byte("*#*1##"))
+		frames <- read()
+		conn.Write([]byte("*#*1##"))
+	}()
+	return l.Addr().String(), frames
+}
+
+func TestPasswordAuthentication(t *testing.T) {
+	plant := loadTestPlant(t)
+	command := plant.ParseFrame("*1*1*12##")
+	address, frames := fakeGateway(t, "25280520")
+	plant.Address, plant.Password = address, "12345"
+	if err := gohome.NewHome(plant).Do(command); err != nil {
+		t.Errorf("command refused: %v", err)
+	}
+	if session, cmd := <-frames, <-frames; session != "*99*0##" || cmd != "*1*1*12##" {
+		t.Errorf("wrong frames received by the gateway: %s %s", session, cmd)
+	}
+	address, _ = fakeGateway(t, "25280520")
+	plant.Address, plant.Password = address, "54321"
+	if err := gohome.NewHome(plant).Do(command); errors.Cause(err) != gohome.ErrAuthentication {
+		t.Errorf("wrong password should be refused: %v", err)
+	}
+}
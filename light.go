@@ -0,0 +1,181 @@
+package gohome
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//ErrNotLightStatus is returned when a message does not carry the state of a light
+var ErrNotLightStatus = errors.New("message is not a light status")
+
+//ErrInvalidLevel is returned when a dimmer level or speed is out of range
+var ErrInvalidLevel = errors.New("invalid light level")
+
+//Lighting dimensions
+const DIMMER_LEVEL_SPEED Dimension = "1"
+const TEMPORIZATION Dimension = "2"
+const REQUIRED_ONLY_ON_LIGHT Dimension = "3"
+const WORKING_TIME_LAMP Dimension = "8"
+const MAX_LEVEL Dimension = "9"
+
+//LightStatus is the state of a light point. Level is in percent (-1 when unknown), Speed is the dimming speed (0 default).
+type LightStatus struct {
+	Where Where
+	On    bool
+	Level int
+	Speed int
+}
+
+//DecodeLightLevel converts the 100-level dimmer encoding (100 off, 101-200 on at 1-100%) to percent
+func DecodeLightLevel(v Value) (int, error) {
+	level, err := strconv.Atoi(string(v))
+	if err != nil || level < 100 || level > 200 {
+		return 0, errors.Wrapf(ErrInvalidLevel, "'%s'", v)
+	}
+	return level - 100, nil
+}
+
+//EncodeLightLevel converts a level in percent to the 100-level dimmer encoding
+func EncodeLightLevel(percent int) (Value, error) {
+	if percent < 0 || percent > 100 {
+		return "", errors.Wrapf(ErrInvalidLevel, "%d is not in 0-100", percent)
+	}
+	return Value(strconv.Itoa(percent + 100)), nil
+}
+
+//NewLightStatus decodes a lighting event or a DIMMER_LEVEL_SPEED dimension response
+func NewLightStatus(msg Message) (LightStatus, error) {
+	if msg.Who == nil || msg.Who.Code != whoLight().Code {
+		return LightStatus{}, errors.Wrapf(ErrNotLightStatus, "WHO is not LIGHT")
+	}
+	switch msg.Kind {
+	case COMMAND:
+		status := LightStatus{Where: msg.Where, Level: -1}
+		n, err := strconv.Atoi(msg.What.Code)
+		switch {
+		case err != nil:
+			return LightStatus{}, errors.Wrapf(ErrNotLightStatus, "what %s", msg.What.Code)
+		case n == 0:
+			status.Level = 0
+		case n >= 2 && n <= 10:
+			status.On, status.Level = true, n*10
+		default:
+			status.On = true
+		}
+		return status, nil
+	case DIMENSIONRESPONSE:
+		if msg.Dimension != DIMMER_LEVEL_SPEED || len(msg.Values) < 2 {
+			return LightStatus{}, errors.Wrapf(ErrNotLightStatus, "dimension %s with %d values", msg.Dimension, len(msg.Values))
+		}
+		level, err := DecodeLightLevel(msg.Values[0])
+		if err != nil {
+			return LightStatus{}, err
+		}
+		speed, err := strconv.Atoi(string(msg.Values[1]))
+		if err != nil {
+			return LightStatus{}, errors.Wrapf(ErrNotLightStatus, "wrong speed %s", msg.Values[1])
+		}
+		return LightStatus{Where: msg.Where, On: level > 0, Level: level, Speed: speed}, nil
+	}
+	return LightStatus{}, errors.Wrapf(ErrNotLightStatus, "kind %s", msg.Kind)
+}
+
+//DecodeTemporization decodes the values of the TEMPORIZATION dimension: H*M*S
+func DecodeTemporization(values []Value) (time.Duration, error) {
+	if len(values) < 3 {
+		return 0, errors.Errorf("%d temporization values instead of 3", len(values))
+	}
+	hms := make([]int, 3)
+	for i := range hms {
+		n, err := strconv.Atoi(string(values[i]))
+		if err != nil {
+			return 0, errors.Errorf("temporization value '%s' is not a number", values[i])
+		}
+		hms[i] = n
+	}
+	return time.Duration(hms[0])*time.Hour + time.Duration(hms[1])*time.Minute + time.Duration(hms[2])*time.Second, nil
+}
+
+//EncodeTemporization returns the values of the TEMPORIZATION dimension, up to 255 hours
+func EncodeTemporization(d time.Duration) ([]Value, error) {
+	if d < time.Second || d >= 256*time.Hour {
+		return nil, errors.Errorf("temporization %s is not in 1s-255h", d)
+	}
+	d = d.Round(time.Second)
+	h := int(d / time.Hour)
+	m := int(d % time.Hour / time.Minute)
+	s := int(d % time.Minute / time.Second)
+	return []Value{Value(strconv.Itoa(h)), Value(strconv.Itoa(m)), Value(strconv.Itoa(s))}, nil
+}
+
+//SetLightLevel brings the dimmer to the level in percent with the given speed (0-255, 0 is the default speed)
+func (h *Home) SetLightLevel(where Where, percent int, speed int) error {
+	level, err := EncodeLightLevel(percent)
+	if err != nil {
+		return err
+	}
+	if speed < 0 || speed > 255 {
+		return errors.Wrapf(ErrInvalidLevel, "speed %d is not in 0-255", speed)
+	}
+	return h.Do(NewDimensionWrite(whoLight(), where, DIMMER_LEVEL_SPEED, level, Value(strconv.Itoa(speed))))
+}
+
+//LightLevel reads the level in percent of the dimmer
+func (h *Home) LightLevel(where Where) (int, error) {
+	answer, err := h.askDimension(whoLight(), where, DIMMER_LEVEL_SPEED)
+	if err != nil {
+		return 0, err
+	}
+	status, err := NewLightStatus(answer)
+	if err != nil {
+		return 0, err
+	}
+	return status.Level, nil
+}
+
+//SetLightTimer switches the light on for the given time
+func (h *Home) SetLightTimer(where Where, d time.Duration) error {
+	values, err := EncodeTemporization(d)
+	if err != nil {
+		return err
+	}
+	return h.Do(NewDimensionWrite(whoLight(), where, TEMPORIZATION, values...))
+}
+
+//LightTimer reads the time left before the light switches off
+func (h *Home) LightTimer(where Where) (time.Duration, error) {
+	answer, err := h.askDimension(whoLight(), where, TEMPORIZATION)
+	if err != nil {
+		return 0, err
+	}
+	return DecodeTemporization(answer.Values)
+}
+
+//LightOnOnly switches the light on at its last level, it has no effect on a light already on
+func (h *Home) LightOnOnly(where Where) error {
+	return h.Do(NewDimensionWrite(whoLight(), where, REQUIRED_ONLY_ON_LIGHT))
+}
+
+//LampWorkingTime reads for how many hours the lamp has been on
+func (h *Home) LampWorkingTime(where Where) (int, error) {
+	answer, err := h.askDimension(whoLight(), where, WORKING_TIME_LAMP)
+	if err != nil {
+		return 0, err
+	}
+	hours, err := strconv.Atoi(string(answer.Values[0]))
+	if err != nil {
+		return 0, errors.Wrapf(ErrNoData, "wrong working time '%s'", answer.Values[0])
+	}
+	return hours, nil
+}
+
+//LightMaxLevel reads the maximum level in percent the dimmer can reach
+func (h *Home) LightMaxLevel(where Where) (int, error) {
+	answer, err := h.askDimension(whoLight(), where, MAX_LEVEL)
+	if err != nil {
+		return 0, err
+	}
+	return DecodeLightLevel(answer.Values[0])
+}
@@ -0,0 +1,69 @@
+package gohome_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestLightStatus(t *testing.T) {
+	plant := loadTestPlant(t)
+	statuses := map[string]gohome.LightStatus{
+		"*#1*12*1*175*20##": gohome.LightStatus{On: true, Level: 75, Speed: 20},
+		"*#1*12*1*100*0##":  gohome.LightStatus{On: false, Level: 0, Speed: 0},
+		"*1*7*12##":         gohome.LightStatus{On: true, Level: 70},
+		"*1*0*12##":         gohome.LightStatus{On: false, Level: 0},
+		"*1*1*12##":         gohome.LightStatus{On: true, Level: -1},
+	}
+	for f, exp := range statuses {
+		s, err := gohome.NewLightStatus(plant.ParseFrame(f))
+		if err != nil {
+			t.Errorf("light status not decoded from %s: %v", f, err)
+			continue
+		}
+		if s.On != exp.On || s.Level != exp.Level || s.Speed != exp.Speed || s.Where.Desc != "kitchen.main" {
+			t.Errorf("wrong light status from %s: %+v", f, s)
+		}
+	}
+	if s, err := gohome.NewLightStatus(plant.ParseFrame("*#1*12*1*250*0##")); err == nil {
+		t.Errorf("level out of range should not be decoded: %+v", s)
+	}
+}
+
+func TestLightDimensionFrames(t *testing.T) {
+	plant := loadTestPlant(t)
+	where, err := plant.WhereFromDesc("kitchen.main")
+	if err != nil {
+		t.Fatalf("Where not found: %v", err)
+	}
+	light := gohome.NewWho("LIGHT")
+	level, err := gohome.EncodeLightLevel(40)
+	if err != nil {
+		t.Errorf("level not encoded: %v", err)
+	}
+	if frame := gohome.NewDimensionWrite(light, where, gohome.DIMMER_LEVEL_SPEED, level, "10").Frame(); frame != "*#1*12*#1*140*10##" {
+		t.Errorf("wrong level frame: %s", frame)
+	}
+	if _, err := gohome.EncodeLightLevel(120); err == nil {
+		t.Errorf("level 120 should not be encoded")
+	}
+	frame := gohome.NewDimensionWrite(light, where, gohome.REQUIRED_ONLY_ON_LIGHT).Frame()
+	if frame != "*#1*12*#3##" {
+		t.Errorf("wrong only on frame: %s", frame)
+	}
+	if ok, kind := gohome.IsValid(frame); !ok || kind != gohome.DIMENSIONSET {
+		t.Errorf("only on frame not valid: %s", kind)
+	}
+	timer, err := gohome.EncodeTemporization(90*time.Minute + 5*time.Second)
+	if err != nil {
+		t.Errorf("temporization not encoded: %v", err)
+	}
+	if frame := gohome.NewDimensionWrite(light, where, gohome.TEMPORIZATION, timer...).Frame(); frame != "*#1*12*#2*1*30*5##" {
+		t.Errorf("wrong temporization frame: %s", frame)
+	}
+	d, err := gohome.DecodeTemporization(plant.ParseFrame("*#1*12*2*0*2*30##").Values)
+	if err != nil || d != 150*time.Second {
+		t.Errorf("wrong temporization %s: %v", d, err)
+	}
+}
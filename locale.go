@@ -0,0 +1,191 @@
+package gohome
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+//ErrLocaleNotFound is returned when the desired locale has not been loaded
+var ErrLocaleNotFound = errors.New("locale not found")
+
+//go:embed data/locales/*.json
+var defaultLocaleData embed.FS
+
+//localName is the translation of a WHO or WHAT: Name is used to render it, Aliases are also accepted as input
+type localName struct {
+	Name    string   `json:"name,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+//matches returns true if the text is the name or one of the aliases, ignoring case
+func (n localName) matches(text string) bool {
+	if n.Name != "" && strings.EqualFold(n.Name, text) {
+		return true
+	}
+	for _, a := range n.Aliases {
+		if strings.EqualFold(a, text) {
+			return true
+		}
+	}
+	return false
+}
+
+//locale holds the names of the WHOs and of their WHATs in a language, keyed by the catalogue descriptions
+type locale struct {
+	Lang string                          `json:"locale"`
+	Who  map[string]localName            `json:"who,omitempty"`
+	What map[string]map[string]localName `json:"what,omitempty"`
+}
+
+//merge adds the names of the other locale, replacing the ones of the same WHO or WHAT
+func (l *locale) merge(o *locale) {
+	for k, n := range o.Who {
+		l.Who[strings.ToUpper(k)] = n
+	}
+	for kw, whats := range o.What {
+		kw = strings.ToUpper(kw)
+		if l.What[kw] == nil {
+			l.What[kw] = map[string]localName{}
+		}
+		for k, n := range whats {
+			l.What[kw][strings.ToUpper(k)] = n
+		}
+	}
+}
+
+//locales holds the loaded locales and the one used to render descriptions, empty for the catalogue names
+var locales = struct {
+	sync.RWMutex
+	all     map[string]*locale
+	current string
+}{all: defaultLocales()}
+
+//defaultLocales decodes the embedded locales, it panics as a broken default locale is a build error
+func defaultLocales() map[string]*locale {
+	all := map[string]*locale{}
+	files, err := defaultLocaleData.ReadDir("data/locales")
+	if err != nil {
+		panic(fmt.Sprintf("embedded locales not found: %v", err))
+	}
+	for _, f := range files {
+		data, err := defaultLocaleData.Open("data/locales/" + f.Name())
+		if err != nil {
+			panic(fmt.Sprintf("embedded locale %s not readable: %v", f.Name(), err))
+		}
+		l, err := decodeLocale(data)
+		data.Close()
+		if err != nil {
+			panic(fmt.Sprintf("wrong embedded locale %s: %v", f.Name(), err))
+		}
+		all[l.Lang] = l
+	}
+	return all
+}
+
+func decodeLocale(r io.Reader) (*locale, error) {
+	read := &locale{}
+	if err := json.NewDecoder(r).Decode(read); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode locale")
+	}
+	if read.Lang == "" {
+		return nil, errors.Wrapf(ErrLocaleNotFound, "locale without name")
+	}
+	l := &locale{Lang: strings.ToLower(read.Lang), Who: map[string]localName{}, What: map[string]map[string]localName{}}
+	l.merge(read)
+	return l, nil
+}
+
+//LoadLocale reads a JSON locale, extending the one with the same name if already loaded
+func LoadLocale(r io.Reader) error {
+	l, err := decodeLocale(r)
+	if err != nil {
+		return err
+	}
+	locales.Lock()
+	defer locales.Unlock()
+	if known, ok := locales.all[l.Lang]; ok {
+		known.merge(l)
+		return nil
+	}
+	locales.all[l.Lang] = l
+	return nil
+}
+
+//SetLocale chooses the locale used to render the descriptions of WHOs and WHATs, empty for the catalogue names
+func SetLocale(lang string) error {
+	lang = strings.ToLower(lang)
+	locales.Lock()
+	defer locales.Unlock()
+	if _, ok := locales.all[lang]; !ok && lang != "" {
+		return errors.Wrapf(ErrLocaleNotFound, "locale %s", lang)
+	}
+	locales.current = lang
+	return nil
+}
+
+//Locales returns the names of the loaded locales
+func Locales() []string {
+	locales.RLock()
+	defer locales.RUnlock()
+	names := make([]string, 0, len(locales.all))
+	for n := range locales.all {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//whoFromAlias returns the catalogue description of the WHO with the given name or alias in any locale
+func whoFromAlias(text string) (string, bool) {
+	locales.RLock()
+	defer locales.RUnlock()
+	for _, l := range locales.all {
+		for desc, n := range l.Who {
+			if n.matches(text) {
+				return desc, true
+			}
+		}
+	}
+	return "", false
+}
+
+//whatFromAlias returns the catalogue description of the WHAT of the WHO with the given name or alias in any locale
+func whatFromAlias(who string, text string) (string, bool) {
+	locales.RLock()
+	defer locales.RUnlock()
+	for _, l := range locales.all {
+		for desc, n := range l.What[who] {
+			if n.matches(text) {
+				return desc, true
+			}
+		}
+	}
+	return "", false
+}
+
+//LocalDesc returns the description of the WHO in the current locale
+func (w *Who) LocalDesc() string {
+	locales.RLock()
+	defer locales.RUnlock()
+	if l, ok := locales.all[locales.current]; ok && l.Who[w.Desc].Name != "" {
+		return l.Who[w.Desc].Name
+	}
+	return w.Desc
+}
+
+//LocalWhat returns the description of a WHAT of the WHO in the current locale
+func (w *Who) LocalWhat(desc string) string {
+	locales.RLock()
+	defer locales.RUnlock()
+	if l, ok := locales.all[locales.current]; ok && l.What[w.Desc][desc].Name != "" {
+		return l.What[w.Desc][desc].Name
+	}
+	return desc
+}
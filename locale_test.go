@@ -0,0 +1,75 @@
+package gohome_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestWhatAliases(t *testing.T) {
+	light, err := gohome.LookupWho("luce")
+	if err != nil || light.Desc != "LIGHT" {
+		t.Fatalf("WHO not found by alias: %v %v", light, err)
+	}
+	whats := map[string]string{
+		"accendi":   "TURN_ON",
+		"SPEGNI":    "TURN_OFF",
+		"on":        "TURN_ON",
+		"Off":       "TURN_OFF",
+		"turn_on":   "TURN_ON",
+		"on_30_sec": "ON_30_SEC",
+		"accendi#3": "TURN_ON",
+	}
+	for text, exp := range whats {
+		what, err := light.WhatFromDesc(text)
+		if err != nil || what.Desc != exp {
+			t.Errorf("wrong what for %s: %v %v", text, what, err)
+		}
+	}
+	aux := gohome.NewWho("AUX")
+	if what, err := aux.WhatFromDesc("on"); err != nil || what.Desc != "ON" {
+		t.Errorf("wrong aux what: %v %v", what, err)
+	}
+	if _, err := light.WhatFromDesc("apri"); err == nil {
+		t.Errorf("aliases of other WHOs should not be accepted")
+	}
+}
+
+func TestLocalizedJSON(t *testing.T) {
+	plant := loadTestPlant(t)
+	if err := gohome.SetLocale("it"); err != nil {
+		t.Fatalf("locale not set: %v", err)
+	}
+	defer gohome.SetLocale("")
+	msg := plant.ParseFrame("*1*1*12##")
+	js := plant.FormatToJSON(msg)
+	if js != `{"who":"LUCE","what":"ACCENDI","where":"kitchen.main","kind":"COMMAND"}` {
+		t.Errorf("wrong localized JSON: %s", js)
+	}
+	if frame := plant.ParseFromJSON(js).Frame(); frame != "*1*1*12##" {
+		t.Errorf("wrong frame from localized JSON: %s", frame)
+	}
+	if err := gohome.SetLocale("klingon"); err == nil {
+		t.Errorf("unknown locale should not be set")
+	}
+}
+
+func TestLoadLocale(t *testing.T) {
+	locale := `{"locale": "it", "what": {"LIGHT": {"TURN_ON": {"name": "ACCENDI", "aliases": ["luce_on"]}}}}`
+	if err := gohome.LoadLocale(strings.NewReader(locale)); err != nil {
+		t.Fatalf("locale not loaded: %v", err)
+	}
+	light := gohome.NewWho("LIGHT")
+	for _, text := range []string{"luce_on", "accendi"} {
+		if what, err := light.WhatFromDesc(text); err != nil || what.Desc != "TURN_ON" {
+			t.Errorf("wrong what for %s: %v %v", text, what, err)
+		}
+	}
+	if what, err := light.WhatFromDesc("spegni"); err != nil || what.Desc != "TURN_OFF" {
+		t.Errorf("locale names lost: %v %v", what, err)
+	}
+	if err := gohome.LoadLocale(strings.NewReader(`{"who": {}}`)); err == nil {
+		t.Errorf("locale without name should not be loaded")
+	}
+}
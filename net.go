@@ -3,6 +3,7 @@ package gohome
 import (
 	"log"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,6 +14,7 @@ var ErrNoData = errors.New("NO DATA")
 var ErrNAK = errors.New("NAK")
 var ErrServerNotFound = errors.New("SERVER NOT FOUND")
 var ErrConnectionFailed = errors.New("CONNECTION FAILED")
+var ErrReadOnly = errors.New("READ ONLY WHO")
 
 //HomeError wraps OWN errors
 type HomeError struct {
@@ -32,50 +34,104 @@ var SystemMessages = map[string]Message{
 }
 
 type Cable struct {
-	address string
+	address  string
+	password string
 }
 
 //Home is a Btcino MyHome plant that can be controlled with a OpenWebNet enabled device (F452 ecc)
 type Home struct {
 	Cable *Cable
-	Plant *Plant
+	plant *Plant
+	mu    sync.RWMutex
 }
 
 //NewHome creates a new Home connected through the given Cable
 func NewHome(plant *Plant) *Home {
 	log.Printf("NewHome")
 	address := plant.ServerAddress()
-	cable := newCable(address)
-	return &Home{Cable: cable, Plant: plant}
+	cable := newCable(address, plant.Password)
+	return &Home{Cable: cable, plant: plant}
 }
 
 //Do some action with your home
 func (h *Home) Do(command Message) error {
 	log.Printf("Home.Do")
-	if command.Kind != COMMAND {
+	if command.Kind != COMMAND && command.Kind != DIMENSIONSET {
 		return errors.Errorf("Message is not a command: %v", command)
 	}
-	return h.Cable.sendCommand(command)
+	if command.Who == nil || command.Who.Code == "" {
+		return errors.Wrapf(ErrWhoNotFound, "command without WHO: %v", command)
+	}
+	if command.Who.ReadOnly {
+		return errors.Wrapf(ErrReadOnly, "cannot send commands to %s", command.Who.Desc)
+	}
+	wheres, err := h.Current().Expand(command.Who, command.Where)
+	if err != nil {
+		return errors.Wrapf(err, "cannot expand where %s", command.Where.Desc)
+	}
+	for _, w := range wheres {
+		command.Where = w
+		if err := h.Cable.sendCommand(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Program sends a scenario programming command in a scenario session
+func (h *Home) Program(command Message) error {
+	log.Printf("Home.Program")
+	if command.Kind != COMMAND || command.Who == nil || command.Who.Code != whoScenario().Code {
+		return errors.Errorf("Message is not a scenario command: %v", command)
+	}
+	return h.Cable.sendInSession(SystemMessages["OPEN_SCENARIO_SESSION"], command)
 }
 
-//Ask the system
+//Ask the system, a floor without group is asked ambient by ambient
 func (h *Home) Ask(request Message) ([]Message, error) {
 	log.Printf("Home.Ask")
-	if request.Kind != REQUEST && request.Kind != SPECIAL {
+	if request.Kind != REQUEST && request.Kind != DIMENSIONGET && request.Kind != SPECIAL {
 		return nil, errors.Errorf("Message is not a request: %v", request)
 	}
-	frames, err := h.Cable.sendRequest(request)
-	if err != nil {
-		return []Message{}, errors.Wrapf(err, "cannot send request frame '%v'", request)
+	if !request.IsSpecial() && (request.Who == nil || request.Who.Code == "") {
+		return nil, errors.Wrapf(ErrWhoNotFound, "request without WHO: %v", request)
 	}
-	//TODO parse di tutte le frame
-	res := make([]Message, len(frames))
-	for i, f := range frames {
-		res[i] = h.Plant.ParseFrame(f)
+	wheres := []Where{request.Where}
+	if !request.IsSpecial() {
+		var err error
+		if wheres, err = h.Current().Expand(request.Who, request.Where); err != nil {
+			return nil, errors.Wrapf(err, "cannot expand where %s", request.Where.Desc)
+		}
+	}
+	res := []Message{}
+	for _, w := range wheres {
+		request.Where = w
+		frames, err := h.Cable.sendRequest(request)
+		if err != nil {
+			return []Message{}, errors.Wrapf(err, "cannot send request frame '%v'", request)
+		}
+		//TODO parse di tutte le frame
+		for _, f := range frames {
+			res = append(res, h.Current().ParseFrame(f))
+		}
 	}
 	return res, nil
 }
 
+//askDimension reads a dimension and returns the first response carrying it
+func (h *Home) askDimension(who *Who, where Where, dim Dimension) (Message, error) {
+	answers, err := h.Ask(NewDimensionRequest(who, where, dim))
+	if err != nil {
+		return Message{}, errors.Wrapf(err, "cannot read dimension %s of %s", dim, who.Desc)
+	}
+	for _, a := range answers {
+		if a.Kind == DIMENSIONRESPONSE && a.Dimension == dim && len(a.Values) > 0 {
+			return a, nil
+		}
+	}
+	return Message{}, errors.Wrapf(ErrNoData, "no dimension %s from %s at '%s'", dim, who.Desc, where.Desc)
+}
+
 func (h *Home) Listen() (<-chan string, chan<- struct{}, <-chan error) {
 	msgChan := make(chan string, 1)
 	signChan := make(chan struct{})
@@ -84,8 +140,8 @@ func (h *Home) Listen() (<-chan string, chan<- struct{}, <-chan error) {
 	return msgChan, signChan, errChan
 }
 
-func newCable(address string) *Cable {
-	c := Cable{address: address}
+func newCable(address string, password string) *Cable {
+	c := Cable{address: address, password: password}
 	return &c
 }
 
@@ -110,17 +166,18 @@ func (c *Cable) connect() (*net.TCPConn, error) {
 }
 
 func (c *Cable) sendCommand(command Message) error {
+	return c.sendInSession(SystemMessages["OPEN_COMMAND_SESSION"], command)
+}
+
+func (c *Cable) sendInSession(session Message, command Message) error {
 	log.Printf("Cable.SendCommmand message:%v", command)
 	conn, err := c.connect()
 	if err != nil {
 		return errors.Wrap(err, "cannot connect")
 	}
 	defer conn.Close()
-	if c.send(conn, SystemMessages["OPEN_COMMAND_SESSION"].Frame()) != nil {
-		return errors.Wrap(err, "cannot open session")
-	}
-	if !c.acked(conn) {
-		return ErrNAK
+	if err := c.openSession(conn, session); err != nil {
+		return err
 	}
 	if c.send(conn, command.Frame()) != nil {
 		return errors.Wrapf(err, "cannot send message %v, ", command)
@@ -138,11 +195,8 @@ func (c *Cable) sendRequest(request Message) ([]string, error) {
 		return nil, errors.Wrap(err, "cannot connect")
 	}
 	defer conn.Close()
-	if c.send(conn, SystemMessages["OPEN_COMMAND_SESSION"].Frame()) != nil {
-		return nil, errors.Wrapf(err, "cannot open command session")
-	}
-	if !c.acked(conn) {
-		return nil, ErrNAK
+	if err := c.openSession(conn, SystemMessages["OPEN_COMMAND_SESSION"]); err != nil {
+		return nil, err
 	}
 	if c.send(conn, request.Frame()) != nil {
 		return nil, errors.Wrapf(err, "cannot send request %v, ", request)
@@ -157,6 +211,9 @@ func (c *Cable) sendRequest(request Message) ([]string, error) {
 		if a == SystemMessages["ACK"].Frame() {
 			break
 		}
+		if a == SystemMessages["NACK"].Frame() {
+			return answers, errors.Wrapf(ErrNAK, "request refused: %v", request)
+		}
 		answers = append(answers, a)
 	}
 	return answers, nil
@@ -171,16 +228,11 @@ func (c *Cable) listen(out chan<- string, in <-chan struct{}, errs chan<- error)
 		return
 	}
 	defer conn.Close()
-	if c.send(conn, SystemMessages["OPEN_EVENT_SESSION"].Frame()) != nil {
+	if err := c.openSession(conn, SystemMessages["OPEN_EVENT_SESSION"]); err != nil {
 		errs <- errors.Wrapf(err, "cannot open event session")
 		close(out)
 		return
 	}
-	if !c.acked(conn) {
-		errs <- ErrNAK
-		close(out)
-		return
-	}
 Listen:
 	for {
 		select {
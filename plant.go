@@ -5,15 +5,20 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 )
 
 type Where struct {
-	Code string
-	Desc string
+	Code   string
+	Desc   string
+	Params []string
+}
+
+//Field returns the WHERE with its parameters as written in a frame
+func (w Where) Field() Field {
+	return Field{Code: w.Code, Params: w.Params}
 }
 
 //GENERAL is the Where that refers to the entire plant
@@ -28,168 +33,340 @@ var ErrLightNotFound = errors.New("light not found")
 //ErrWhereNotInPlant is returned whene a where numeric code is not found in the current plant configuration
 var ErrWhereNotInPlant = errors.New("WHERE not found in the current plant configuration")
 
+//ErrGroupNotFound is returned when the desired group is not found in the conf file
+var ErrGroupNotFound = errors.New("group not found")
+
+//Ambient is a room of the plant (0-10) with its devices. Lights and Shutters are the v1 configuration,
+//moved to Devices by NewPlant.
 type Ambient struct {
-	Num    int            `json:"num"`
-	Lights map[string]int `json:"lights"`
+	Num       int               `json:"num"`
+	Interface string            `json:"interface,omitempty"`
+	Devices   map[string]Device `json:"devices,omitempty"`
+	Lights    map[string]int    `json:"lights,omitempty"`
+	Shutters  map[string]int    `json:"shutters,omitempty"`
 }
 
+//Group is a set of points that can be addressed together with a single WHERE (#1-#255)
+type Group struct {
+	Num     int      `json:"num"`
+	Members []string `json:"members"`
+}
+
+//Plant is the building: its floors group the ambients, that hold the devices
 type Plant struct {
-	Name     string             `json:"name"`
-	Num      int                `json:"num"`
-	Address  string             `json:"address"`
-	Ambients map[string]Ambient `json:"ambients"`
+	Version   int                 `json:"version,omitempty"`
+	Name      string              `json:"name"`
+	Num       int                 `json:"num"`
+	Address   string              `json:"address"`
+	Password  string              `json:"password,omitempty"`
+	Floors    map[string]Floor    `json:"floors,omitempty"`
+	Ambients  map[string]Ambient  `json:"ambients"`
+	Groups    map[string]Group    `json:"groups,omitempty"`
+	Zones     map[string]Zone     `json:"zones,omitempty"`
+	Alarms    map[string]int      `json:"alarms,omitempty"`
+	Keypads   map[string]string   `json:"keypads,omitempty"`
+	Meters    map[string]int      `json:"meters,omitempty"`
+	Loads     map[string]int      `json:"loads,omitempty"`
+	Audio     *Audio              `json:"audio,omitempty"`
+	Panels    map[string]int      `json:"panels,omitempty"`
+	Locks     map[string]int      `json:"locks,omitempty"`
+	Modules   map[string]int      `json:"modules,omitempty"`
+	Scenarios map[string]Scenario `json:"scenarios,omitempty"`
+	Aux       map[string]int      `json:"aux,omitempty"`
+}
+
+//whereResolvers return the WHERE code of the named elements of the plant described by <prefix><name>
+var whereResolvers = map[string]func(p *Plant, name string) (string, error){
+	groupPrefix:    (*Plant).groupCode,
+	zonePrefix:     (*Plant).zoneCode,
+	alarmPrefix:    (*Plant).alarmCode,
+	keypadPrefix:   (*Plant).keypadCode,
+	meterPrefix:    (*Plant).meterCode,
+	loadPrefix:     (*Plant).loadCode,
+	audioPrefix:    (*Plant).audioCode,
+	sourcePrefix:   (*Plant).sourceCode,
+	panelPrefix:    (*Plant).panelCode,
+	lockPrefix:     (*Plant).lockCode,
+	modulePrefix:   (*Plant).moduleCode,
+	scenarioPrefix: (*Plant).scenarioCode,
+	auxPrefix:      (*Plant).auxCode,
+}
+
+//whereDecoders decode the WHERE codes of the WHOs that do not use the ambient/light addressing
+var whereDecoders = map[string]func(p *Plant, code string) (Where, error){
+	"4":    (*Plant).zoneFromCode,
+	"1004": (*Plant).zoneFromCode,
+	"5":    (*Plant).alarmFromCode,
+	"9":    (*Plant).auxFromCode,
+	"3":    (*Plant).energyFromCode,
+	"18":   (*Plant).energyFromCode,
+	"0":    (*Plant).moduleFromCode,
+	"17":   (*Plant).scenarioFromCode,
+	"6":    (*Plant).doorFromCode,
+	"16":   (*Plant).audioFromCode,
+	"22":   (*Plant).audioFromCode,
+	"15":   (*Plant).keypadFromCode,
+	"25":   (*Plant).keypadFromCode,
+}
+
+//pointName returns the name of the device connected to the given point
+func (a Ambient) pointName(num int) (string, bool) {
+	for kd, d := range a.Devices {
+		if n, ok := d.pointNum(); ok && n == num {
+			return kd, true
+		}
+	}
+	return "", false
 }
 
-//NewPlant load a plant configuration from a json file. Return a pointer to the Plant that will be used.
+//busParams returns the WHERE parameters for an ambient on a private riser
+func (a Ambient) busParams() []string {
+	if a.Interface == "" {
+		return nil
+	}
+	return []string{localBus, a.Interface}
+}
+
+//NewPlant load a plant configuration from a JSON, YAML or TOML file. Return a pointer to the Plant that will be used.
+//The problems of the configuration are logged, NewStrictPlant refuses them.
 func NewPlant(config io.Reader) (*Plant, error) {
+	plant, err := decodePlant(config)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range plant.Validate() {
+		log.Printf("plant configuration problem at %v", pr)
+	}
+	return plant, nil
+}
+
+//decodePlant reads a plant configuration and migrates it to the current version
+func decodePlant(config io.Reader) (*Plant, error) {
 	if config == nil {
 		return nil, errors.New("Plant configuration is nil")
 	}
-	decoder := json.NewDecoder(config)
-	plant := Plant{}
-	err := decoder.Decode(&plant)
+	data, err := plantJSON(config)
 	if err != nil {
 		return nil, err
 	}
+	plant := Plant{}
+	if err := json.Unmarshal(data, &plant); err != nil {
+		return nil, err
+	}
+	if plant.Version > PLANT_VERSION {
+		return nil, errors.Errorf("plant configuration version %d is not supported", plant.Version)
+	}
+	plant.migrate()
 	return &plant, nil
 }
 
-//NewWhere returns a
+//WhereFromDesc returns the where defined in the plant config file by: GENERAL, <prefix>:<name>, <ambient>[.<device>],
+//<floor> or <floor>/<ambient>[.<device>]
 func (p *Plant) WhereFromDesc(text string) (Where, error) {
 	var noWhere Where
+	text, params := splitParams(text)
 	if strings.ToUpper(text) == "GENERAL" {
 		where := GENERAL
+		where.Params = params
 		return where, nil
 	}
-	split := strings.Split(text, ".")
-	if len(split) == 2 {
-		amb, ok := p.Ambients[split[0]]
+	if i := strings.Index(text, ":"); i > 0 {
+		resolve, ok := whereResolvers[text[:i+1]]
 		if !ok {
-			return noWhere, ErrAmbientNotFound
+			return noWhere, errors.Wrapf(ErrWhereNotInPlant, "unknown prefix in where: %s", text)
 		}
-		lig, ok := amb.Lights[split[1]]
+		resolved, err := resolve(p, text[i+1:])
+		if err != nil {
+			return noWhere, err
+		}
+		code, defParams := splitParams(resolved)
+		if params == nil {
+			params = defParams
+		}
+		return Where{Code: code, Desc: text, Params: params}, nil
+	}
+	if i := strings.Index(text, floorSeparator); i > 0 {
+		floor, ok := p.Floors[text[:i]]
 		if !ok {
-			return noWhere, ErrLightNotFound
+			return noWhere, ErrFloorNotFound
+		}
+		if !floor.contains(strings.Split(text[i+1:], ".")[0]) {
+			return noWhere, errors.Wrapf(ErrAmbientNotFound, "ambient not in floor %s", text[:i])
+		}
+		where, err := p.WhereFromDesc(withParams(text[i+1:], params))
+		if err != nil {
+			return noWhere, err
 		}
-		where := Where{fmt.Sprintf("%d%d", amb.Num, lig), text}
+		where.Desc = text
 		return where, nil
 	}
-	if len(split) == 1 {
-		amb, ok := p.Ambients[split[0]]
+	split := strings.Split(text, ".")
+	if len(split) > 2 {
+		return noWhere, ErrLightNotFound
+	}
+	amb, ok := p.Ambients[split[0]]
+	if _, isFloor := p.Floors[text]; !ok && isFloor {
+		return p.floorWhere(text, params)
+	}
+	if !ok {
+		return noWhere, ErrAmbientNotFound
+	}
+	if params == nil {
+		params = amb.busParams()
+	}
+	if len(split) == 2 {
+		dev, ok := amb.Devices[split[1]]
 		if !ok {
-			return noWhere, ErrAmbientNotFound
+			return noWhere, ErrLightNotFound
 		}
-		where := Where{fmt.Sprintf("%d", amb.Num), text}
-		return where, nil
+		if !dev.isPoint() {
+			return noWhere, errors.Wrapf(ErrInvalidAddress, "device %s of WHO %s is not a point of the ambient", text, dev.Who)
+		}
+		code, err := dev.code(amb)
+		if err != nil {
+			return noWhere, err
+		}
+		return Where{Code: code, Desc: text, Params: params}, nil
 	}
-	return noWhere, ErrLightNotFound
+	code, err := ambientCode(amb.Num)
+	if err != nil {
+		return noWhere, err
+	}
+	return Where{Code: code, Desc: text, Params: params}, nil
 }
 
-//Decode returns where defined by the ambient and light names in the plant config file: <ambient>[.<light>]
+//WhereFromCode returns the where with the description from the plant config file: GENERAL, group:<group>, <ambient>[.<device>]
 func (p *Plant) WhereFromCode(code string) (Where, error) {
 	if code == "" {
 		return Where{}, nil
 	}
-	var wtext string
-	if len(code) < 1 || len(code) > 2 {
-		return Where{}, ErrWhereNotInPlant
-	}
-	amb, err := strconv.Atoi(string(code[0:1]))
+	code, params := splitParams(code)
+	addr, err := parseAddress(code)
 	if err != nil {
 		return Where{}, errors.Wrapf(ErrWhereNotInPlant, "where: %v", code)
 	}
-	for ka, a := range p.Ambients {
-		if a.Num == amb {
-			wtext = ka
-			if len(code) == 2 {
-				lig, err := strconv.Atoi(string(code[1:2]))
-				if err != nil {
-					return Where{}, errors.Wrapf(ErrWhereNotInPlant, "where: %v", code)
-				}
-				for kl, pl := range a.Lights {
-					if pl == lig {
-						wtext = wtext + "." + kl
-					}
-				}
+	where := Where{Code: code, Params: params}
+	if addr.general {
+		where.Desc = GENERAL.Desc
+		return where, nil
+	}
+	if addr.group > 0 {
+		for kg, g := range p.Groups {
+			if g.Num == addr.group {
+				where.Desc = groupPrefix + kg
 			}
 		}
+		return where, nil
+	}
+	ka, a, ok := p.ambientOn(addr.ambient, params)
+	if !ok && busInterface(params) != "" {
+		return Where{}, errors.Wrapf(ErrWhereNotInPlant, "no ambient %d on interface %s: %v", addr.ambient, busInterface(params), code)
+	}
+	if !ok {
+		return where, nil
+	}
+	where.Desc = ka
+	if addr.point == 0 {
+		return where, nil
+	}
+	if kp, ok := a.pointName(addr.point); ok {
+		where.Desc = ka + "." + kp
+	}
+	return where, nil
+}
+
+//ambientOn returns the ambient with the number for a WHERE with the given parameters: the one on the same private
+//riser, otherwise the one on the main bus. The ambients on other risers are never used. The parameters are kept
+//in the WHERE, so its description resolves back to the same code.
+func (p *Plant) ambientOn(num int, params []string) (string, Ambient, bool) {
+	iface := busInterface(params)
+	name, rank := "", 2
+	for ka, a := range p.Ambients {
+		if a.Num != num {
+			continue
+		}
+		var r int
+		switch {
+		case a.Interface == iface:
+			r = 0
+		case a.Interface == "":
+			r = 1
+		default:
+			continue
+		}
+		if r < rank || (r == rank && ka < name) {
+			name, rank = ka, r
+		}
+	}
+	if rank == 2 {
+		return "", Ambient{}, false
 	}
-	return Where{code, wtext}, nil
+	return name, p.Ambients[name], true
+}
+
+//groupCode returns the WHERE code of the group with the given name
+func (p *Plant) groupCode(name string) (string, error) {
+	group, ok := p.Groups[name]
+	if !ok {
+		return "", ErrGroupNotFound
+	}
+	return groupCode(group.Num)
 }
 
 //ParseFrame parse a OWN frame and returns a structured message.
 func (p *Plant) ParseFrame(frame string) Message {
-	fmt.Printf("Checking frame: %s\n", frame)
-	message := Message{}
+	log.Printf("Checking frame: %s\n", frame)
 	valid, msgkind := IsValid(frame)
 	if !valid {
-		fmt.Printf("Frame not valid: %s\n", frame)
-		message.Kind = INVALID
-		return message
-	}
-	if msgkind == REQUEST {
-		t := regexpRequest.FindStringSubmatch(string(frame))
-		fmt.Printf("Frame (%s) recognized as REQUEST: %v\n", frame, t)
-		message.Who = NewWho(t[1])
-		where, err := p.WhereFromCode(t[2])
-		if err != nil {
-			log.Printf("Frame not valid: %s due to: %v\n", frame, err)
-			message.Kind = INVALID
-			return message
-		}
-		message.Where = where
-		message.Kind = REQUEST
-		return message
-	}
-	if msgkind == COMMAND {
-		t := regexpCommand.FindStringSubmatch(string(frame))
-		log.Printf("Frame (%s) recognized as COMMAND: %v\n", frame, t)
-		message.Who = NewWho(t[1])
-		what, err := message.Who.WhatFromCode(t[2])
+		log.Printf("Frame not valid: %s\n", frame)
+		return Message{Kind: INVALID}
+	}
+	if msgkind == SPECIAL {
+		return Message{Kind: SPECIAL, special: frame}
+	}
+	f, err := NewFrame(frame)
+	if err != nil {
+		log.Printf("Frame not valid: %s due to: %v\n", frame, err)
+		return Message{Kind: INVALID}
+	}
+	return p.messageFromFrame(f)
+}
+
+//messageFromFrame builds a Message decoding the syntax tree of a frame with the plant configuration
+func (p *Plant) messageFromFrame(f *Frame) Message {
+	log.Printf("Frame (%s) recognized as %s: %+v\n", f.Raw, f.Kind, f)
+	who, err := LookupWho(f.Who)
+	if err != nil {
+		//the WHO of a frame is a valid code, even if missing in the catalogue
+		who = &Who{Code: f.Who, Actions: map[string]string{}, Dimensions: map[string]string{}}
+	}
+	message := Message{Who: who}
+	if f.Kind == COMMAND {
+		what, err := message.Who.WhatFromCode(f.What.Code)
 		if err != nil {
-			log.Printf("Frame what not valid: %s due to: %v\n", frame, err)
-			message.Kind = INVALID
-			return message
+			log.Printf("Frame what not valid: %s due to: %v\n", f.Raw, err)
+			return Message{Kind: INVALID}
 		}
+		what.Params = f.What.Params
 		message.What = what
-		where, err := p.WhereFromCode(t[3])
-		if err != nil {
-			log.Printf("Frame where not valid: %s due to: %v\n", frame, err)
-			message.Kind = INVALID
-			return message
-		}
-		message.Where = where
-		message.Kind = COMMAND
-		return message
-	}
-	if msgkind == DIMENSIONGET {
-		t := regexpDimensionGet.FindStringSubmatch(string(frame))
-		fmt.Printf("Frame (%s) recognized as DIMENSIONGET: %v\n", frame, t)
-		message.Who = NewWho(t[1])
-		where, err := p.WhereFromCode(t[2])
-		if err != nil {
-			log.Printf("Frame where not valid: %s due to: %v\n", frame, err)
-			message.Kind = INVALID
-			return message
-		}
-		message.Where = where
-		message.Kind = DIMENSIONGET
-		return message
-	}
-	if msgkind == DIMENSIONSET {
-		t := regexpDimensionSet.FindStringSubmatch(string(frame))
-		fmt.Printf("Frame (%s) recognized as DIMENSIONSET: %v\n", frame, t)
-		message.Who = NewWho(t[1])
-		where, err := p.WhereFromCode(t[2])
-		if err != nil {
-			log.Printf("Frame where not valid: %s due to: %v\n", frame, err)
-			message.Kind = INVALID
-			return message
+	}
+	decode, ok := whereDecoders[f.Who]
+	if !ok {
+		decode = (*Plant).WhereFromCode
+	}
+	where, err := decode(p, f.Where.String())
+	if err != nil {
+		log.Printf("Frame where not valid: %s due to: %v\n", f.Raw, err)
+		return Message{Kind: INVALID}
+	}
+	message.Where = where
+	if f.Kind == DIMENSIONGET || f.Kind == DIMENSIONSET || f.Kind == DIMENSIONRESPONSE {
+		message.Dimension = Dimension(f.Dimension.String())
+		for _, v := range f.Values {
+			message.Values = append(message.Values, Value(v))
 		}
-		message.Where = where
-		message.Kind = DIMENSIONSET
-		return message
 	}
+	message.Kind = f.Kind
 	return message
 }
 
@@ -212,8 +389,12 @@ func (p *Plant) ParseFromJSON(jsonMessage string) Message {
 		fmt.Printf("who not found\n")
 		return Message{Kind: INVALID}
 	}
-	msg.Who = NewWho(who)
-	var err error
+	w, err := LookupWho(who)
+	if err != nil {
+		log.Printf("Plant.ParseFromJSON - unknown WHO %s: %v", who, err)
+		return Message{Kind: INVALID}
+	}
+	msg.Who = w
 	var wa What
 	var we Where
 	what, ok := mapMsg["what"].(string)
@@ -228,6 +409,14 @@ func (p *Plant) ParseFromJSON(jsonMessage string) Message {
 	}
 	we, err = p.WhereFromDesc(where)
 	msg.Where = we
+	if dim, ok := mapMsg["dimension"].(string); ok {
+		msg.Dimension = Dimension(dim)
+	}
+	if vals, ok := mapMsg["values"].([]interface{}); ok {
+		for _, v := range vals {
+			msg.Values = append(msg.Values, Value(fmt.Sprint(v)))
+		}
+	}
 	kind := mapMsg["kind"].(string)
 	msg.Kind = kind
 	if err != nil {
@@ -243,8 +432,14 @@ func (p *Plant) ServerAddress() string {
 	return p.Address
 }
 
-//ExportPlant the current plant configuration to the given file
+//ExportPlant the current plant configuration to the given file, in the current version. The format is given by
+//the extension of the file, JSON if unknown.
 func (p *Plant) ExportPlant(f io.Writer) error {
-	encoder := json.NewEncoder(f)
-	return encoder.Encode(p)
+	format := FORMAT_JSON
+	if named, ok := f.(interface{ Name() string }); ok {
+		if byName, err := FormatFromName(named.Name()); err == nil {
+			format = byName
+		}
+	}
+	return p.ExportPlantAs(f, format)
 }
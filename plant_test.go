@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/pkg/errors"
+
 	"github.com/savardiego/gohome"
 )
 
@@ -18,6 +22,25 @@ func makeTestPlant(t *testing.T) *gohome.Plant {
 	return p
 }
 
+//loadTestPlant returns the plant of the test house in testdata/casa.json
+func loadTestPlant(t *testing.T) *gohome.Plant {
+	return loadPlantFile(t, "testdata/casa.json")
+}
+
+//loadPlantFile returns the plant of a configuration file of the tests
+func loadPlantFile(t *testing.T, path string) *gohome.Plant {
+	config, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("cannot open %s: %v", path, err)
+	}
+	defer config.Close()
+	plant, err := gohome.NewPlant(config)
+	if err != nil {
+		t.Fatalf("cannot load plant from %s: %v", path, err)
+	}
+	return plant
+}
+
 func TestLoadPlant(t *testing.T) {
 	config, err := os.Open("testdata/casa.json")
 	if err != nil {
@@ -202,7 +225,7 @@ func TestWhoFromFrame(t *testing.T) {
 			break
 		}
 		if msg.Who.Code != expWho.Code {
-			t.Errorf("%d - Wrong WHO decoded: exp:%s actual:%s", i, expWho, msg.Who)
+			t.Errorf("%d - Wrong WHO decoded: exp:%v actual:%v", i, expWho, msg.Who)
 		}
 	}
 }
@@ -252,7 +275,7 @@ func TestDecodeWhoFromFrame(t *testing.T) {
 			break
 		}
 		if msg.Who.Desc != expWho {
-			t.Errorf("%d - Wrong WHO decoded: exp:%s actual:%s", i, expWho, msg.Who)
+			t.Errorf("%d - Wrong WHO decoded: exp:%s actual:%v", i, expWho, msg.Who)
 		}
 	}
 }
@@ -282,16 +305,17 @@ func TestDecodeWhatFromFrame(t *testing.T) {
 func TestFormatToJSON(t *testing.T) {
 	plant := makeTestPlant(t)
 	exp := map[string]string{
-		"*1*1*11##": "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"kitchen.table\",\"kind\":\"COMMAND\"}",
-		"*1*1*12##": "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"kitchen.main\",\"kind\":\"COMMAND\"}",
-		"*#1*1*##":  "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
-		"*1*1*22##": "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"living.tv\",\"kind\":\"COMMAND\"}",
-		"*#1*12##":  "{\"who\":\"LIGHT\",\"what\":\"\",\"where\":\"kitchen.main\",\"kind\":\"REQUEST\"}",
-		"*1*1*2##":  "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"living\",\"kind\":\"COMMAND\"}",
-		"*3*2##":    "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
-		"*1*2##":    "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
-		"*1*1##":    "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
-		"":          "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
+		"*1*1*11##":        "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"kitchen.table\",\"kind\":\"COMMAND\"}",
+		"*1*1*12##":        "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"kitchen.main\",\"kind\":\"COMMAND\"}",
+		"*#1*1*##":         "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
+		"*1*1*22##":        "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"living.tv\",\"kind\":\"COMMAND\"}",
+		"*#1*12##":         "{\"who\":\"LIGHT\",\"what\":\"\",\"where\":\"kitchen.main\",\"kind\":\"REQUEST\"}",
+		"*1*1*2##":         "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"living\",\"kind\":\"COMMAND\"}",
+		"*#1*12*1*150*0##": "{\"who\":\"LIGHT\",\"what\":\"\",\"where\":\"kitchen.main\",\"dimension\":\"1\",\"values\":[\"150\",\"0\"],\"kind\":\"DIMENSIONRESPONSE\"}",
+		"*3*2##":           "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
+		"*1*2##":           "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
+		"*1*1##":           "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
+		"":                 "{\"who\":\"\",\"what\":\"\",\"where\":\"\",\"kind\":\"INVALID\"}",
 	}
 	for m, ts := range exp {
 		message := plant.ParseFrame(m)
@@ -305,11 +329,12 @@ func TestFormatToJSON(t *testing.T) {
 func TestParseFromJSON(t *testing.T) {
 	plant := makeTestPlant(t)
 	exp := map[string]string{
-		"*1*1*11##": "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"kitchen.table\",\"kind\":\"COMMAND\"}",
-		"*1*1*12##": "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"kitchen.main\",\"kind\":\"COMMAND\"}",
-		"*1*1*22##": "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"living.tv\",\"kind\":\"COMMAND\"}",
-		"*#1*12##":  "{\"who\":\"LIGHT\",\"what\":\"\",\"where\":\"kitchen.main\",\"kind\":\"REQUEST\"}",
-		"*1*1*2##":  "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"living\",\"kind\":\"COMMAND\"}",
+		"*1*1*11##":         "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"kitchen.table\",\"kind\":\"COMMAND\"}",
+		"*1*1*12##":         "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"kitchen.main\",\"kind\":\"COMMAND\"}",
+		"*1*1*22##":         "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"living.tv\",\"kind\":\"COMMAND\"}",
+		"*#1*12##":          "{\"who\":\"LIGHT\",\"what\":\"\",\"where\":\"kitchen.main\",\"kind\":\"REQUEST\"}",
+		"*1*1*2##":          "{\"who\":\"LIGHT\",\"what\":\"TURN_ON\",\"where\":\"living\",\"kind\":\"COMMAND\"}",
+		"*#1*12*#1*150*0##": "{\"who\":\"LIGHT\",\"where\":\"kitchen.main\",\"dimension\":\"1\",\"values\":[\"150\",\"0\"],\"kind\":\"DIMENSIONSET\"}",
 	}
 	for _, ts := range exp {
 		frame := plant.ParseFromJSON(ts).Frame()
@@ -358,3 +383,156 @@ func TestWhereFromDesc(t *testing.T) {
 	}
 
 }
+
+func TestParamsRoundTrip(t *testing.T) {
+	plant := makeTestPlant(t)
+	exp := map[string]string{
+		"*1*1#3*12##":       "{\"who\":\"LIGHT\",\"what\":\"TURN_ON#3\",\"where\":\"kitchen.main\",\"kind\":\"COMMAND\"}",
+		"*1*0*12#4#01##":    "{\"who\":\"LIGHT\",\"what\":\"TURN_OFF\",\"where\":\"kitchen.main#4#01\",\"kind\":\"COMMAND\"}",
+		"*1*5#1#2*2#4#03##": "{\"who\":\"LIGHT\",\"what\":\"SET_50#1#2\",\"where\":\"living#4#03\",\"kind\":\"COMMAND\"}",
+		"*#1*21#4#01##":     "{\"who\":\"LIGHT\",\"what\":\"\",\"where\":\"living.sofa#4#01\",\"kind\":\"REQUEST\"}",
+		"*1*5#1#2*2##":      "{\"who\":\"LIGHT\",\"what\":\"SET_50#1#2\",\"where\":\"living\",\"kind\":\"COMMAND\"}",
+		"*1*0*21#1##":       "{\"who\":\"LIGHT\",\"what\":\"TURN_OFF\",\"where\":\"living.sofa#1\",\"kind\":\"COMMAND\"}",
+		"*1*1#3*0#4#01##":   "{\"who\":\"LIGHT\",\"what\":\"TURN_ON#3\",\"where\":\"GENERAL#4#01\",\"kind\":\"COMMAND\"}",
+	}
+	for f, js := range exp {
+		msg := plant.ParseFrame(f)
+		if msg.Frame() != f {
+			t.Errorf("frame with parameters not rendered back: %s != %s", msg.Frame(), f)
+		}
+		if j := plant.FormatToJSON(msg); j != js {
+			t.Errorf("decoded JSON for message '%s' is wrong: %s!=%s", f, j, js)
+		}
+		if frame := plant.ParseFromJSON(js).Frame(); frame != f {
+			t.Errorf("frame from JSON %s is wrong: %s!=%s", js, frame, f)
+		}
+	}
+	who := gohome.NewWho("LIGHT")
+	what, err := who.WhatFromDesc("SET_50#1")
+	if err != nil || what.Code != "5" || len(what.Params) != 1 || what.Params[0] != "1" {
+		t.Errorf("WHAT with parameters not decoded: %v (err: %v)", what, err)
+	}
+}
+
+func TestExtendedWhere(t *testing.T) {
+	plant := loadTestPlant(t)
+	wheres := map[string]string{
+		"GENERAL":          "0",
+		"group:downstairs": "#1",
+		"group:night":      "#12",
+		"living.shelf":     "0212",
+		"garage":           "00",
+		"garage.door":      "0003",
+		"studio":           "100#4#01",
+		"studio.desk":      "1005#4#01",
+		"studio.desk#4#02": "1005#4#02",
+	}
+	for desc, code := range wheres {
+		w, err := plant.WhereFromDesc(desc)
+		if err != nil {
+			t.Errorf("failed to decode where (%s) due to %v", desc, err)
+		}
+		if w.Field().String() != code {
+			t.Errorf("decoded where code (%s) is not the expected (%s)", w.Field().String(), code)
+		}
+	}
+	codes := map[string]string{
+		"#1":        "group:downstairs",
+		"#12":       "group:night",
+		"#200":      "",
+		"0212":      "living.shelf",
+		"00":        "garage",
+		"0003":      "garage.door",
+		"100#4#01":  "studio",
+		"1005#4#01": "studio.desk",
+		"0212#4#01": "living.shelf",
+		"1005":      "",
+	}
+	for code, desc := range codes {
+		w, err := plant.WhereFromCode(code)
+		if err != nil {
+			t.Errorf("failed to decode where (%s) due to %v", code, err)
+		}
+		if w.Desc != desc {
+			t.Errorf("decoded where (%s) is not the expected (%s)", w.Desc, desc)
+		}
+	}
+	for _, code := range []string{"10", "0016", "1101", "#0", "#256", "123", "12345", "1005#4#02"} {
+		if w, err := plant.WhereFromCode(code); err == nil {
+			t.Errorf("where (%s) should be invalid, decoded as %v", code, w)
+		}
+	}
+	if _, err := plant.WhereFromDesc("group:upstairs"); err != gohome.ErrGroupNotFound {
+		t.Errorf("group:upstairs should not be found (err: %v)", err)
+	}
+	msg := plant.ParseFrame("*1*0*1005#4#01##")
+	if msg.Where.Desc != "studio.desk" || msg.Frame() != "*1*0*1005#4#01##" {
+		t.Errorf("local bus frame wrongly decoded: %s %s", msg.Where.Desc, msg.Frame())
+	}
+}
+
+func TestPlantV2(t *testing.T) {
+	plant := loadPlantFile(t, "testdata/casa_v2.json")
+	sofa, err := plant.Device("living", "sofa")
+	if err != nil {
+		t.Fatalf("device not found: %v", err)
+	}
+	if !sofa.HasCapability("level") || sofa.Metadata == nil || sofa.Metadata.Icon != "lamp" {
+		t.Errorf("wrong dimmer: %+v", sofa)
+	}
+	if window, _ := plant.Device("living", "window"); window.Who != "2" || !window.HasCapability("up_down") {
+		t.Errorf("wrong shutter defaults: %+v", window)
+	}
+	wheres := map[string]string{
+		"living.sofa":   "21",
+		"living.fan":    "23",
+		"living.window": "25",
+	}
+	for desc, code := range wheres {
+		where, err := plant.WhereFromDesc(desc)
+		if err != nil || where.Code != code {
+			t.Errorf("wrong where for %s: %v %v", desc, where, err)
+		}
+	}
+	if where, err := plant.WhereFromDesc("living.probe"); errors.Cause(err) != gohome.ErrInvalidAddress {
+		t.Errorf("probe is not a point and should not be a where: %v (err: %v)", where, err)
+	}
+	if where, _ := plant.WhereFromCode("23"); where.Desc != "living.fan" {
+		t.Errorf("wrong where from code: %v", where)
+	}
+}
+
+func TestPlantMigration(t *testing.T) {
+	plant := loadTestPlant(t)
+	if plant.Version != gohome.PLANT_VERSION {
+		t.Errorf("plant not migrated: version %d", plant.Version)
+	}
+	tv, err := plant.Device("living", "tv")
+	if err != nil || tv.Type != gohome.DEVICE_LIGHT || tv.Who != "1" || tv.Address != "2" {
+		t.Errorf("wrong migrated light: %+v %v", tv, err)
+	}
+	door, err := plant.Device("living", "door")
+	if err != nil || door.Type != gohome.DEVICE_SHUTTER || door.Who != "2" || door.Address != "6" {
+		t.Errorf("wrong migrated shutter: %+v %v", door, err)
+	}
+	if len(plant.Ambients["living"].Lights) > 0 {
+		t.Errorf("v1 lights not removed")
+	}
+	exported := bytes.Buffer{}
+	if err := plant.ExportPlant(&exported); err != nil {
+		t.Fatalf("plant not exported: %v", err)
+	}
+	if strings.Contains(exported.String(), `"lights"`) || !strings.Contains(exported.String(), `"version":2`) {
+		t.Errorf("plant not exported as v2: %s", exported.String())
+	}
+	reloaded, err := gohome.NewPlant(&exported)
+	if err != nil {
+		t.Fatalf("exported plant not loaded: %v", err)
+	}
+	if !reflect.DeepEqual(plant, reloaded) {
+		t.Errorf("exported plant differs from the original")
+	}
+	if _, err := gohome.NewPlant(strings.NewReader(`{"version": 3}`)); err == nil {
+		t.Errorf("unknown version should not be loaded")
+	}
+}
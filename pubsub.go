@@ -73,7 +73,7 @@ func (p *PubSub) Listen(home *Home) (<-chan Message, <-chan error) {
 func (p *PubSub) listen(home *Home, incoming chan Message, errors chan error) {
 	err := p.inSub.Receive(p.ctx, func(ctx context.Context, m *pubsub.Message) {
 		msgJSON := string(m.Data)
-		msg := home.Plant.ParseFromJSON(msgJSON)
+		msg := home.Current().ParseFromJSON(msgJSON)
 		log.Printf("Received p/s message: %s, %v", msgJSON, msg)
 		incoming <- msg
 		m.Ack()
@@ -0,0 +1,160 @@
+package gohome
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//ErrGatewayChanged is returned when a reloaded plant has another gateway, that needs a new Home
+var ErrGatewayChanged = errors.New("gateway changed")
+
+//Current returns the plant in use, that can be replaced by Reload while the home is working
+func (h *Home) Current() *Plant {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.plant
+}
+
+//Plant returns the plant in use like Current, it replaces the Plant field that could not be read safely while
+//the plant is reloaded
+func (h *Home) Plant() *Plant {
+	return h.Current()
+}
+
+//Reload replaces the plant in use with the given one and returns their differences. The plant is refused if it
+//has problems or another gateway.
+func (h *Home) Reload(plant *Plant) ([]string, error) {
+	if problems := plant.Validate(); len(problems) > 0 {
+		return nil, errors.WithStack(problems)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if plant.Address != h.plant.Address || plant.Password != h.plant.Password {
+		return nil, errors.Wrapf(ErrGatewayChanged, "gateway %s cannot be changed while running", h.plant.Address)
+	}
+	diff := h.plant.Diff(plant)
+	h.plant = plant
+	return diff, nil
+}
+
+//Diff returns the ambients and devices added (+), removed (-) or changed (~) in the other plant
+func (p *Plant) Diff(other *Plant) []string {
+	diff := []string{}
+	ambients := []string{}
+	for ka := range p.Ambients {
+		ambients = append(ambients, ka)
+	}
+	for ka := range other.Ambients {
+		if _, ok := p.Ambients[ka]; !ok {
+			ambients = append(ambients, ka)
+		}
+	}
+	sort.Strings(ambients)
+	for _, ka := range ambients {
+		before, inP := p.Ambients[ka]
+		after, inOther := other.Ambients[ka]
+		switch {
+		case !inOther:
+			diff = append(diff, fmt.Sprintf("- ambient %s", ka))
+		case !inP:
+			diff = append(diff, fmt.Sprintf("+ ambient %s: %s", ka, after.desc()))
+		case before.desc() != after.desc():
+			diff = append(diff, fmt.Sprintf("~ ambient %s: %s -> %s", ka, before.desc(), after.desc()))
+		}
+		devices := []string{}
+		for kd := range before.Devices {
+			devices = append(devices, kd)
+		}
+		for kd := range after.Devices {
+			if _, ok := before.Devices[kd]; !ok {
+				devices = append(devices, kd)
+			}
+		}
+		sort.Strings(devices)
+		for _, kd := range devices {
+			bd, inBefore := before.Devices[kd]
+			ad, inAfter := after.Devices[kd]
+			switch {
+			case !inAfter:
+				diff = append(diff, fmt.Sprintf("- device %s.%s", ka, kd))
+			case !inBefore:
+				diff = append(diff, fmt.Sprintf("+ device %s.%s: %s", ka, kd, ad.desc()))
+			case bd.desc() != ad.desc():
+				diff = append(diff, fmt.Sprintf("~ device %s.%s: %s -> %s", ka, kd, bd.desc(), ad.desc()))
+			}
+		}
+	}
+	return diff
+}
+
+//desc describes the address of the ambient
+func (a Ambient) desc() string {
+	return withParams(fmt.Sprintf("num %d", a.Num), a.busParams())
+}
+
+//desc describes the type and the address of the device
+func (d Device) desc() string {
+	return fmt.Sprintf("%s, WHO %s, address %s", d.Type, d.Who, d.Address)
+}
+
+//Watch checks every interval the configuration file at path and, when it changes, reloads the plant read by load.
+//Refused plants are sent to the error channel and the home keeps the current one. Send to the signal channel to stop,
+//the error channel is closed when the watch ends.
+func (h *Home) Watch(path string, interval time.Duration, load func(config io.Reader) (*Plant, error)) (chan<- struct{}, <-chan error) {
+	signChan := make(chan struct{})
+	errChan := make(chan error)
+	last, _ := os.Stat(path)
+	go func() {
+		defer close(errChan)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-signChan:
+				return
+			case <-ticker.C:
+			}
+			info, err := os.Stat(path)
+			if err != nil || (last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size()) {
+				continue
+			}
+			last = info
+			if err := h.reloadFile(path, load); err != nil {
+				select {
+				case errChan <- err:
+				case <-signChan:
+					return
+				}
+			}
+		}
+	}()
+	return signChan, errChan
+}
+
+//reloadFile loads the plant from the file and replaces the current one, logging the differences
+func (h *Home) reloadFile(path string, load func(config io.Reader) (*Plant, error)) error {
+	config, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "cannot open configuration file %s", path)
+	}
+	defer config.Close()
+	plant, err := load(config)
+	if err != nil {
+		return errors.Wrapf(err, "cannot load plant from %s", path)
+	}
+	diff, err := h.Reload(plant)
+	if err != nil {
+		return errors.Wrapf(err, "plant from %s refused", path)
+	}
+	log.Printf("Home.Watch - plant reloaded from %s with %d changes", path, len(diff))
+	for _, d := range diff {
+		log.Printf("Home.Watch - %s", d)
+	}
+	return nil
+}
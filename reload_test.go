@@ -0,0 +1,124 @@
+package gohome_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/savardiego/gohome"
+)
+
+func TestPlantDiff(t *testing.T) {
+	plant := loadTestPlant(t)
+	other := loadTestPlant(t)
+	delete(other.Ambients, "garage")
+	other.Ambients["attic"] = gohome.Ambient{Num: 3, Devices: map[string]gohome.Device{"lamp": gohome.NewDevice(gohome.DEVICE_LIGHT, "1")}}
+	kitchen := other.Ambients["kitchen"]
+	kitchen.Devices["main"] = gohome.NewDevice(gohome.DEVICE_DIMMER, "3")
+	delete(kitchen.Devices, "table")
+	studio := other.Ambients["studio"]
+	studio.Interface = "02"
+	other.Ambients["studio"] = studio
+	expected := []string{
+		"+ ambient attic: num 3",
+		"+ device attic.lamp: light, WHO 1, address 1",
+		"- ambient garage",
+		"- device garage.door",
+		"~ device kitchen.main: light, WHO 1, address 2 -> dimmer, WHO 1, address 3",
+		"- device kitchen.table",
+		"~ ambient studio: num 10#4#01 -> num 10#4#02",
+	}
+	if diff := plant.Diff(other); strings.Join(diff, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong diff:\n%s", strings.Join(diff, "\n"))
+	}
+	if diff := plant.Diff(loadTestPlant(t)); len(diff) > 0 {
+		t.Errorf("same plants should have no diff: %v", diff)
+	}
+}
+
+func TestReload(t *testing.T) {
+	home := gohome.NewHome(loadTestPlant(t))
+	invalid := loadTestPlant(t)
+	invalid.Ambients["attic"] = gohome.Ambient{Num: 11}
+	if _, err := home.Reload(invalid); err == nil {
+		t.Errorf("invalid plant should be refused")
+	}
+	moved := loadTestPlant(t)
+	moved.Address = "192.168.0.36:20000"
+	if _, err := home.Reload(moved); errors.Cause(err) != gohome.ErrGatewayChanged {
+		t.Errorf("plant with another gateway should be refused: %v", err)
+	}
+	if _, ok := home.Current().Ambients["attic"]; ok || home.Current().Address != "192.168.0.35:20000" {
+		t.Errorf("refused plants should not be used")
+	}
+	valid := loadTestPlant(t)
+	delete(valid.Ambients["kitchen"].Devices, "table")
+	diff, err := home.Reload(valid)
+	if err != nil || len(diff) != 1 || home.Current() != valid {
+		t.Errorf("valid plant should be used: %v %v", diff, err)
+	}
+	if home.Plant() != valid {
+		t.Errorf("Plant should return the reloaded plant")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	config, err := ioutil.ReadFile("testdata/casa.json")
+	if err != nil {
+		t.Fatalf("cannot read json file: %v", err)
+	}
+	dir, err := ioutil.TempDir("", "gohome")
+	if err != nil {
+		t.Fatalf("cannot create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "gohome.json")
+	if err := ioutil.WriteFile(path, config, 0644); err != nil {
+		t.Fatalf("cannot write config: %v", err)
+	}
+	home := gohome.NewHome(loadTestPlant(t))
+	stop, errs := home.Watch(path, 10*time.Millisecond, gohome.NewPlant)
+	changed := strings.Replace(string(config), `"table": 1`, `"table": 13`, 1)
+	if err := ioutil.WriteFile(path, []byte(changed), 0644); err != nil {
+		t.Fatalf("cannot write config: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for home.Current().Ambients["kitchen"].Devices["table"].Address != "13" {
+		if time.Now().After(deadline) {
+			t.Fatalf("plant not reloaded")
+		}
+		select {
+		case err := <-errs:
+			t.Fatalf("plant not reloaded: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	moved := strings.Replace(changed, "192.168.0.35", "192.168.10.35", 1)
+	if err := ioutil.WriteFile(path, []byte(moved), 0644); err != nil {
+		t.Fatalf("cannot write config: %v", err)
+	}
+	select {
+	case err := <-errs:
+		if errors.Cause(err) != gohome.ErrGatewayChanged {
+			t.Errorf("wrong error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("plant with another gateway not refused")
+	}
+	if home.Current().Address != "192.168.0.35:20000" {
+		t.Errorf("refused plant should not be used")
+	}
+	close(stop)
+	select {
+	case _, open := <-errs:
+		if open {
+			t.Errorf("error channel should be closed when the watch ends")
+		}
+	case <-time.After(2 * time.Second):
+		t.Errorf("error channel not closed")
+	}
+}
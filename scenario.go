@@ -0,0 +1,219 @@
+package gohome
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+//ErrScenarioNotFound is returned when the desired scenario or scenario module is not found in the conf file
+var ErrScenarioNotFound = errors.New("scenario not found")
+
+//ErrNotScenarioEvent is returned when a message is not a scenario event
+var ErrNotScenarioEvent = errors.New("message is not a scenario event")
+
+//modulePrefix introduces a scenario module in a WHERE description: module:<name>
+const modulePrefix = "module:"
+
+//scenarioPrefix introduces a scenario of the MH200N programmer in a WHERE description: scenario:<name>
+const scenarioPrefix = "scenario:"
+
+//Scenario is a scenario stored in a scenario module (Num 1-16) or, when Module is empty, in the MH200N programmer (Num 1-300)
+type Scenario struct {
+	Num    int    `json:"num"`
+	Module string `json:"module,omitempty"`
+}
+
+//ScenarioEvent is the activation or the programming of a scenario. Num is 0 for the events of the whole module.
+type ScenarioEvent struct {
+	Where  Where
+	Num    int
+	Action string
+}
+
+//NewScenarioEvent decodes a scenario (WHO 0) or scenario programming (WHO 17) message
+func NewScenarioEvent(msg Message) (ScenarioEvent, error) {
+	if msg.Who == nil || msg.Kind != COMMAND || msg.What.Desc == "" {
+		return ScenarioEvent{}, ErrNotScenarioEvent
+	}
+	switch msg.Who.Code {
+	case whoScenario().Code:
+		event := ScenarioEvent{Where: msg.Where, Action: msg.What.Desc}
+		if n, err := strconv.Atoi(msg.What.Code); err == nil && n <= 16 {
+			event.Num, event.Action = n, "START_SCENARIO"
+		} else if len(msg.What.Params) > 0 {
+			event.Num, _ = strconv.Atoi(msg.What.Params[0])
+		}
+		return event, nil
+	case whoScenarioProgramming().Code:
+		n, err := strconv.Atoi(msg.Where.Code)
+		if err != nil {
+			return ScenarioEvent{}, errors.Wrapf(ErrNotScenarioEvent, "wrong scenario %s", msg.Where.Code)
+		}
+		return ScenarioEvent{Where: msg.Where, Num: n, Action: msg.What.Desc}, nil
+	}
+	return ScenarioEvent{}, ErrNotScenarioEvent
+}
+
+//String returns a human readable description of the event
+func (e ScenarioEvent) String() string {
+	if e.Num == 0 {
+		return fmt.Sprintf("%s on '%s'", e.Action, e.Where.Desc)
+	}
+	return fmt.Sprintf("%s %d on '%s'", e.Action, e.Num, e.Where.Desc)
+}
+
+//moduleCode returns the WHERE code of the scenario module with the given name
+func (p *Plant) moduleCode(name string) (string, error) {
+	n, ok := p.Modules[name]
+	if !ok {
+		return "", ErrScenarioNotFound
+	}
+	if n < 1 || n > 99 {
+		return "", errors.Wrapf(ErrInvalidAddress, "scenario module %d is not in 1-99", n)
+	}
+	return fmt.Sprintf("%02d", n), nil
+}
+
+//scenarioCode returns the WHERE code of a scenario of the MH200N programmer, that is its number
+func (p *Plant) scenarioCode(name string) (string, error) {
+	s, ok := p.Scenarios[name]
+	if !ok {
+		return "", ErrScenarioNotFound
+	}
+	if s.Module != "" {
+		return "", errors.Wrapf(ErrScenarioNotFound, "scenario %s is stored in module %s", name, s.Module)
+	}
+	if s.Num < 1 || s.Num > 300 {
+		return "", errors.Wrapf(ErrInvalidAddress, "scenario %d is not in 1-300", s.Num)
+	}
+	return strconv.Itoa(s.Num), nil
+}
+
+//moduleFromCode decodes the WHERE of a scenario frame with the modules of the plant
+func (p *Plant) moduleFromCode(code string) (Where, error) {
+	if code == "" {
+		return Where{}, nil
+	}
+	code, params := splitParams(code)
+	where := Where{Code: code, Params: params}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return Where{}, errors.Wrapf(ErrWhereNotInPlant, "where: %v", code)
+	}
+	for km, m := range p.Modules {
+		if m == n {
+			where.Desc = modulePrefix + km
+		}
+	}
+	return where, nil
+}
+
+//scenarioFromCode decodes the WHERE of a scenario programming frame with the MH200N scenarios of the plant
+func (p *Plant) scenarioFromCode(code string) (Where, error) {
+	if code == "" {
+		return Where{}, nil
+	}
+	code, params := splitParams(code)
+	where := Where{Code: code, Params: params}
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return Where{}, errors.Wrapf(ErrWhereNotInPlant, "where: %v", code)
+	}
+	for ks, s := range p.Scenarios {
+		if s.Module == "" && s.Num == n {
+			where.Desc = scenarioPrefix + ks
+		}
+	}
+	return where, nil
+}
+
+//ScenarioCommand returns the message that performs the action on the named scenario. Scenarios of a module accept
+//START_SCENARIO, START_PROGRAMMING, STOP_PROGRAMMING, ERASE and ENABLE_SCENARIO or DISABLE_SCENARIO, that unlock
+//or lock the whole module, those of the MH200N the WHO 17 actions.
+func (p *Plant) ScenarioCommand(name string, action string) (Message, error) {
+	s, ok := p.Scenarios[name]
+	if !ok {
+		return Message{}, errors.Wrapf(ErrScenarioNotFound, "scenario %s", name)
+	}
+	if s.Module == "" {
+		where, err := p.WhereFromDesc(scenarioPrefix + name)
+		if err != nil {
+			return Message{}, err
+		}
+		what, err := whoScenarioProgramming().WhatFromDesc(action)
+		if err != nil {
+			return Message{}, errors.Wrapf(err, "action %s not available on MH200N scenarios", action)
+		}
+		return NewCommand(whoScenarioProgramming(), what, where), nil
+	}
+	where, err := p.WhereFromDesc(modulePrefix + s.Module)
+	if err != nil {
+		return Message{}, errors.Wrapf(err, "module %s of scenario %s", s.Module, name)
+	}
+	if s.Num < 1 || s.Num > 16 {
+		return Message{}, errors.Wrapf(ErrInvalidAddress, "scenario %d is not in 1-16", s.Num)
+	}
+	num := strconv.Itoa(s.Num)
+	switch action {
+	case "START_SCENARIO":
+		what, _ := whoScenario().WhatFromCode(num)
+		return NewCommand(whoScenario(), what, where), nil
+	case "START_PROGRAMMING", "STOP_PROGRAMMING", "ERASE":
+		what, _ := whoScenario().WhatFromDesc(action)
+		what.Params = []string{num}
+		return NewCommand(whoScenario(), what, where), nil
+	case "ENABLE_SCENARIO", "DISABLE_SCENARIO":
+		lock := map[string]string{"ENABLE_SCENARIO": "UNLOCK", "DISABLE_SCENARIO": "LOCK"}[action]
+		what, err := whoScenario().WhatFromDesc(lock)
+		if err != nil {
+			return Message{}, errors.Wrapf(err, "action %s not available on scenario modules", action)
+		}
+		return NewCommand(whoScenario(), what, where), nil
+	}
+	return Message{}, errors.Errorf("action %s not available on scenario modules", action)
+}
+
+func (h *Home) scenario(name string, action string) error {
+	cmd, err := h.Current().ScenarioCommand(name, action)
+	if err != nil {
+		return err
+	}
+	if cmd.Who.Code == whoScenario().Code && cmd.What.Params != nil {
+		return h.Program(cmd)
+	}
+	return h.Do(cmd)
+}
+
+//RunScenario activates the scenario
+func (h *Home) RunScenario(name string) error {
+	return h.scenario(name, "START_SCENARIO")
+}
+
+//StopScenario stops a running scenario of the MH200N
+func (h *Home) StopScenario(name string) error {
+	return h.scenario(name, "STOP_SCENARIO")
+}
+
+//EnableScenario enables or disables a scenario of the MH200N. The scenarios of a module are enabled or disabled
+//unlocking or locking their module, with all its scenarios.
+func (h *Home) EnableScenario(name string, enable bool) error {
+	if enable {
+		return h.scenario(name, "ENABLE_SCENARIO")
+	}
+	return h.scenario(name, "DISABLE_SCENARIO")
+}
+
+//RecordScenario starts or stops the recording of a scenario in its module
+func (h *Home) RecordScenario(name string, start bool) error {
+	if start {
+		return h.scenario(name, "START_PROGRAMMING")
+	}
+	return h.scenario(name, "STOP_PROGRAMMING")
+}
+
+//EraseScenario erases a scenario from its module
+func (h *Home) EraseScenario(name string) error {
+	return h.scenario(name, "ERASE")
+}
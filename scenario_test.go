@@ -0,0 +1,71 @@
+package gohome_test
+
+import (
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestScenarioCommand(t *testing.T) {
+	plant := loadTestPlant(t)
+	frames := map[[2]string]string{
+		[2]string{"cinema", "START_SCENARIO"}:      "*0*3*01##",
+		[2]string{"cinema", "START_PROGRAMMING"}:   "*0*40#3*01##",
+		[2]string{"cinema", "ERASE"}:               "*0*42#3*01##",
+		[2]string{"cinema", "DISABLE_SCENARIO"}:    "*0*43*01##",
+		[2]string{"cinema", "ENABLE_SCENARIO"}:     "*0*44*01##",
+		[2]string{"goodnight", "START_SCENARIO"}:   "*17*1*12##",
+		[2]string{"goodnight", "DISABLE_SCENARIO"}: "*17*4*12##",
+	}
+	for c, exp := range frames {
+		cmd, err := plant.ScenarioCommand(c[0], c[1])
+		if err != nil {
+			t.Errorf("command %v not built: %v", c, err)
+			continue
+		}
+		if cmd.Frame() != exp {
+			t.Errorf("wrong frame for %v: %s", c, cmd.Frame())
+		}
+	}
+	if _, err := plant.ScenarioCommand("cinema", "STOP_SCENARIO"); err == nil {
+		t.Errorf("module scenarios cannot be stopped")
+	}
+	if _, err := plant.ScenarioCommand("party", "START_SCENARIO"); err == nil {
+		t.Errorf("unknown scenario should not be found")
+	}
+}
+
+func TestScenarioEvent(t *testing.T) {
+	plant := loadTestPlant(t)
+	events := map[string]gohome.ScenarioEvent{
+		"*0*3*01##":    gohome.ScenarioEvent{Num: 3, Action: "START_SCENARIO", Where: gohome.Where{Desc: "module:hall"}},
+		"*0*41#3*01##": gohome.ScenarioEvent{Num: 3, Action: "STOP_PROGRAMMING", Where: gohome.Where{Desc: "module:hall"}},
+		"*0*46*01##":   gohome.ScenarioEvent{Num: 0, Action: "MEMORY_FULL", Where: gohome.Where{Desc: "module:hall"}},
+		"*17*2*12##":   gohome.ScenarioEvent{Num: 12, Action: "STOP_SCENARIO", Where: gohome.Where{Desc: "scenario:goodnight"}},
+	}
+	for f, exp := range events {
+		e, err := gohome.NewScenarioEvent(plant.ParseFrame(f))
+		if err != nil {
+			t.Errorf("scenario event not decoded from %s: %v", f, err)
+			continue
+		}
+		if e.Num != exp.Num || e.Action != exp.Action || e.Where.Desc != exp.Where.Desc {
+			t.Errorf("wrong scenario event from %s: %+v", f, e)
+		}
+	}
+	if e, err := gohome.NewScenarioEvent(plant.ParseFrame("*1*1*21##")); err == nil {
+		t.Errorf("light message should not be a scenario event: %+v", e)
+	}
+}
+
+func TestEnableModuleScenario(t *testing.T) {
+	plant := loadTestPlant(t)
+	address, frames := fakeGateway(t, "25280520")
+	plant.Address, plant.Password = address, "12345"
+	if err := gohome.NewHome(plant).EnableScenario("cinema", false); err != nil {
+		t.Errorf("module scenario not disabled: %v", err)
+	}
+	if session, cmd := <-frames, <-frames; session != "*99*0##" || cmd != "*0*43*01##" {
+		t.Errorf("wrong frames received by the gateway: %s %s", session, cmd)
+	}
+}
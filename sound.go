@@ -0,0 +1,254 @@
+package gohome
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrAudioNotFound is returned when the desired sound zone, speaker or source is not found in the conf file
+var ErrAudioNotFound = errors.New("sound zone or source not found")
+
+//ErrNotAudioStatus is returned when a message does not carry the state of a sound zone
+var ErrNotAudioStatus = errors.New("message is not a sound status")
+
+//audioPrefix introduces a sound zone or speaker in a WHERE description: audio:<zone>[.<speaker>]
+const audioPrefix = "audio:"
+
+//sourcePrefix introduces a sound source in a WHERE description: source:<name>
+const sourcePrefix = "source:"
+
+//Sound dimensions
+const VOLUME Dimension = "1"
+
+//Audio is the sound diffusion system of the plant. Who is 16 (default) or 22, Sources are numbered 1-9.
+type Audio struct {
+	Who     string               `json:"who,omitempty"`
+	Zones   map[string]AudioZone `json:"zones"`
+	Sources map[string]int       `json:"sources,omitempty"`
+}
+
+//AudioZone is an area of the sound system (0-9) with its speakers (amplifiers 0-9)
+type AudioZone struct {
+	Area     int            `json:"area"`
+	Speakers map[string]int `json:"speakers,omitempty"`
+}
+
+//AudioStatus is the state of a sound zone or speaker. Volume (0-31) and Source are -1 when unknown.
+type AudioStatus struct {
+	Where  Where
+	On     bool
+	Volume int
+	Source int
+}
+
+//soundWho returns the WHO of the sound system of the plant
+func (p *Plant) soundWho() *Who {
+	if p.Audio != nil && p.Audio.Who == whoSoundSystem().Code {
+		return whoSoundSystem()
+	}
+	return whoSound()
+}
+
+//audioCode returns the WHERE code of a sound zone (WHO 16: #A, WHO 22: 3#A) or speaker (WHO 16: AP, WHO 22: 3#A#P)
+func (p *Plant) audioCode(name string) (string, error) {
+	if p.Audio == nil {
+		return "", ErrAudioNotFound
+	}
+	split := strings.Split(name, ".")
+	zone, ok := p.Audio.Zones[split[0]]
+	if !ok || len(split) > 2 {
+		return "", ErrAudioNotFound
+	}
+	if zone.Area < 0 || zone.Area > 9 {
+		return "", errors.Wrapf(ErrInvalidAddress, "sound area %d is not in 0-9", zone.Area)
+	}
+	sys22 := p.soundWho().Code == whoSoundSystem().Code
+	if len(split) == 1 {
+		if sys22 {
+			return fmt.Sprintf("3#%d", zone.Area), nil
+		}
+		return fmt.Sprintf("#%d", zone.Area), nil
+	}
+	speaker, ok := zone.Speakers[split[1]]
+	if !ok {
+		return "", ErrAudioNotFound
+	}
+	if speaker < 0 || speaker > 9 {
+		return "", errors.Wrapf(ErrInvalidAddress, "speaker %d is not in 0-9", speaker)
+	}
+	if sys22 {
+		return fmt.Sprintf("3#%d#%d", zone.Area, speaker), nil
+	}
+	return fmt.Sprintf("%d%d", zone.Area, speaker), nil
+}
+
+//sourceCode returns the WHERE code of a sound source (WHO 16: 10S, WHO 22: 2#S)
+func (p *Plant) sourceCode(name string) (string, error) {
+	if p.Audio == nil {
+		return "", ErrAudioNotFound
+	}
+	source, ok := p.Audio.Sources[name]
+	if !ok {
+		return "", ErrAudioNotFound
+	}
+	if source < 1 || source > 9 {
+		return "", errors.Wrapf(ErrInvalidAddress, "sound source %d is not in 1-9", source)
+	}
+	if p.soundWho().Code == whoSoundSystem().Code {
+		return fmt.Sprintf("2#%d", source), nil
+	}
+	return fmt.Sprintf("10%d", source), nil
+}
+
+//audioFromCode decodes the WHERE of a sound frame with the zones, speakers and sources of the plant
+func (p *Plant) audioFromCode(code string) (Where, error) {
+	if code == "" {
+		return Where{}, nil
+	}
+	c, params := splitParams(code)
+	where := Where{Code: c, Params: params}
+	if p.Audio == nil {
+		return where, nil
+	}
+	var area, point, source = -1, -1, -1
+	switch {
+	case c == "3" && len(params) > 0:
+		area, _ = strconv.Atoi(params[0])
+		if len(params) > 1 {
+			point, _ = strconv.Atoi(params[1])
+		}
+	case c == "2" && len(params) > 0:
+		source, _ = strconv.Atoi(params[0])
+	case strings.HasPrefix(c, "#") && len(c) == 2:
+		area = int(c[1] - '0')
+	case len(c) == 3 && strings.HasPrefix(c, "10"):
+		source = int(c[2] - '0')
+	case len(c) == 2:
+		area, point = int(c[0]-'0'), int(c[1]-'0')
+	}
+	for ks, s := range p.Audio.Sources {
+		if source >= 0 && s == source {
+			where.Desc = sourcePrefix + ks
+		}
+	}
+	for kz, z := range p.Audio.Zones {
+		if area < 0 || z.Area != area {
+			continue
+		}
+		if point < 0 {
+			where.Desc = audioPrefix + kz
+			continue
+		}
+		for ks, s := range z.Speakers {
+			if s == point {
+				where.Desc = audioPrefix + kz + "." + ks
+			}
+		}
+	}
+	return where, nil
+}
+
+//Update updates the status with the information carried by a sound message
+func (s *AudioStatus) Update(msg Message) error {
+	if msg.Who == nil || (msg.Who.Code != whoSound().Code && msg.Who.Code != whoSoundSystem().Code) {
+		return errors.Wrapf(ErrNotAudioStatus, "WHO is not SOUND")
+	}
+	s.Where = msg.Where
+	switch msg.Kind {
+	case COMMAND:
+		switch msg.What.Desc {
+		case "ON":
+			s.On = true
+		case "OFF":
+			s.On = false
+		}
+		return nil
+	case DIMENSIONRESPONSE:
+		if len(msg.Values) == 0 {
+			return errors.Wrapf(ErrNotAudioStatus, "no values")
+		}
+		v, err := strconv.Atoi(string(msg.Values[0]))
+		if err != nil {
+			return errors.Wrapf(ErrNotAudioStatus, "wrong value %s", msg.Values[0])
+		}
+		switch msg.Who.Dimensions[string(msg.Dimension)] {
+		case "VOLUME":
+			s.Volume = v
+		case "ACTIVE_SOURCE":
+			s.Source = v
+		case "STATE":
+			s.On = v == 1
+		}
+		return nil
+	}
+	return errors.Wrapf(ErrNotAudioStatus, "kind %s", msg.Kind)
+}
+
+func (h *Home) soundCommand(action string, where Where) error {
+	who := h.Current().soundWho()
+	what, err := who.WhatFromDesc(action)
+	if err != nil {
+		return err
+	}
+	return h.Do(NewCommand(who, what, where))
+}
+
+//SetAudio turns on or off a sound zone or speaker
+func (h *Home) SetAudio(where Where, on bool) error {
+	if on {
+		return h.soundCommand("ON", where)
+	}
+	return h.soundCommand("OFF", where)
+}
+
+//SetSource turns on the given source and plays it in the sound zone or speaker
+func (h *Home) SetSource(where Where, source Where) error {
+	if err := h.soundCommand("SOURCE_ON", source); err != nil {
+		return errors.Wrapf(err, "cannot turn on source %s", source.Desc)
+	}
+	return h.SetAudio(where, true)
+}
+
+//SetVolume sets the volume (0-31) of a sound zone or speaker
+func (h *Home) SetVolume(where Where, volume int) error {
+	if volume < 0 || volume > 31 {
+		return errors.Errorf("volume %d is not in 0-31", volume)
+	}
+	return h.Do(NewDimensionWrite(h.Current().soundWho(), where, VOLUME, Value(strconv.Itoa(volume))))
+}
+
+//StepVolume raises (steps > 0) or lowers (steps < 0) the volume of a sound zone or speaker
+func (h *Home) StepVolume(where Where, steps int) error {
+	action := "VOLUME_UP"
+	if steps < 0 {
+		action, steps = "VOLUME_DOWN", -steps
+	}
+	if steps == 0 || steps > 31 {
+		return errors.Errorf("volume steps %d is not in 1-31", steps)
+	}
+	if steps > 1 {
+		action = fmt.Sprintf("%s#%d", action, steps)
+	}
+	return h.soundCommand(action, where)
+}
+
+//AudioStatus asks a sound zone or speaker its state, volume and source
+func (h *Home) AudioStatus(where Where) (AudioStatus, error) {
+	status := AudioStatus{Where: where, Volume: -1, Source: -1}
+	who := h.Current().soundWho()
+	answers, err := h.Ask(NewRequest(who, What{}, where))
+	if err != nil {
+		return status, errors.Wrapf(err, "cannot get sound status of %s", where.Desc)
+	}
+	for _, a := range answers {
+		status.Update(a)
+	}
+	volume, err := h.askDimension(who, where, VOLUME)
+	if err == nil {
+		status.Update(volume)
+	}
+	return status, nil
+}
@@ -0,0 +1,83 @@
+package gohome_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestAudioWhere(t *testing.T) {
+	plant := loadTestPlant(t)
+	who := gohome.NewWho("SOUND")
+	frames := map[string]string{
+		"audio:living":       "*16*0*#2##",
+		"audio:living.right": "*16*0*22##",
+		"source:stereo":      "*16*0*102##",
+	}
+	on, err := who.WhatFromDesc("ON")
+	if err != nil {
+		t.Errorf("What not found: %v", err)
+	}
+	for desc, exp := range frames {
+		where, err := plant.WhereFromDesc(desc)
+		if err != nil {
+			t.Errorf("Where %s not found: %v", desc, err)
+			continue
+		}
+		frame := gohome.NewCommand(who, on, where).Frame()
+		if frame != exp {
+			t.Errorf("wrong frame for %s: %s", desc, frame)
+		}
+		if msg := plant.ParseFrame(frame); msg.Where.Desc != desc {
+			t.Errorf("wrong where decoded from %s: %v", frame, msg.Where)
+		}
+	}
+	if _, err := plant.WhereFromDesc("audio:living.center"); err == nil {
+		t.Errorf("unknown speaker should not be found")
+	}
+}
+
+func TestAudioSystem22(t *testing.T) {
+	plant, err := gohome.NewPlant(strings.NewReader(`{"name":"flat","audio":{"who":"22","zones":{"bath":{"area":3,"speakers":{"ceiling":1}}},"sources":{"radio":4}}}`))
+	if err != nil {
+		t.Fatalf("plant not loaded: %v", err)
+	}
+	who := gohome.NewWho("SOUND_SYSTEM")
+	frames := map[string]string{
+		"audio:bath":         "*22*1*3#3##",
+		"audio:bath.ceiling": "*22*1*3#3#1##",
+		"source:radio":       "*22*1*2#4##",
+	}
+	on, _ := who.WhatFromDesc("ON")
+	for desc, exp := range frames {
+		where, err := plant.WhereFromDesc(desc)
+		if err != nil {
+			t.Errorf("Where %s not found: %v", desc, err)
+			continue
+		}
+		frame := gohome.NewCommand(who, on, where).Frame()
+		if frame != exp {
+			t.Errorf("wrong frame for %s: %s", desc, frame)
+		}
+		if msg := plant.ParseFrame(frame); msg.Where.Desc != desc {
+			t.Errorf("wrong where decoded from %s: %v", frame, msg.Where)
+		}
+	}
+}
+
+func TestAudioStatus(t *testing.T) {
+	plant := loadTestPlant(t)
+	status := gohome.AudioStatus{Volume: -1, Source: -1}
+	for _, f := range []string{"*16*0*22##", "*#16*22*1*18##", "*#16*22*7*2##"} {
+		if err := status.Update(plant.ParseFrame(f)); err != nil {
+			t.Errorf("status not updated with %s: %v", f, err)
+		}
+	}
+	if !status.On || status.Volume != 18 || status.Source != 2 || status.Where.Desc != "audio:living.right" {
+		t.Errorf("wrong sound status: %+v", status)
+	}
+	if err := status.Update(plant.ParseFrame("*1*1*21##")); err == nil {
+		t.Errorf("light message should not update a sound status")
+	}
+}
@@ -0,0 +1,237 @@
+package gohome
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ErrZoneNotFound is returned when the desired thermoregulation zone is not found in the conf file
+var ErrZoneNotFound = errors.New("zone not found")
+
+//ErrInvalidTemperature is returned when a temperature is not in the 4-digit OWN encoding or out of range
+var ErrInvalidTemperature = errors.New("invalid temperature")
+
+//zonePrefix introduces a thermoregulation zone in a WHERE description: zone:<name>
+const zonePrefix = "zone:"
+
+//CENTRAL is the Where of the thermoregulation central unit
+var CENTRAL Where = Where{Code: "#0", Desc: "CENTRAL"}
+
+//Setpoint functions of the SET_POINT dimension
+const SETPOINT_HEATING = "1"
+const SETPOINT_COOLING = "2"
+const SETPOINT_GENERIC = "3"
+
+//Zone is a thermoregulation zone of the plant (1-99), Ambients lists the ambients it heats
+type Zone struct {
+	Num      int      `json:"num"`
+	Ambients []string `json:"ambients,omitempty"`
+}
+
+//ZoneStatus collects what a zone reports when asked its status. Temperatures are in °C.
+type ZoneStatus struct {
+	Where       Where
+	Mode        string
+	Temperature float64
+	Setpoint    float64
+	Offset      string
+	Valves      []string
+	Actuators   []string
+}
+
+var thermoOffsets = map[string]string{
+	"00": "0",
+	"01": "+1",
+	"11": "-1",
+	"02": "+2",
+	"12": "-2",
+	"03": "+3",
+	"13": "-3",
+	"4":  "OFF",
+	"5":  "ANTIFREEZE",
+}
+
+var thermoValves = map[string]string{
+	"0": "OFF",
+	"1": "ON",
+	"2": "OPENED",
+	"3": "CLOSED",
+	"4": "STOP",
+	"5": "OFF_FAN_COIL",
+	"6": "ON_SPEED_1",
+	"7": "ON_SPEED_2",
+	"8": "ON_SPEED_3",
+	"9": "ON_FAN_COIL",
+}
+
+//DecodeTemperature converts the OWN temperature encoding (sign digit + tenths of degree, eg. 0215 = 21.5, 1050 = -5.0) to °C
+func DecodeTemperature(v Value) (float64, error) {
+	t := string(v)
+	if len(t) != 4 || (t[0] != '0' && t[0] != '1') {
+		return 0, errors.Wrapf(ErrInvalidTemperature, "'%s'", t)
+	}
+	tenths, err := strconv.Atoi(t[1:])
+	if err != nil {
+		return 0, errors.Wrapf(ErrInvalidTemperature, "'%s'", t)
+	}
+	temp := float64(tenths) / 10
+	if t[0] == '1' {
+		temp = -temp
+	}
+	return temp, nil
+}
+
+//EncodeTemperature converts a temperature in °C to the OWN encoding, rounded at 0.5 °C as required by setpoints
+func EncodeTemperature(temp float64) (Value, error) {
+	if temp < -99.9 || temp > 99.9 {
+		return "", errors.Wrapf(ErrInvalidTemperature, "%.1f is out of range", temp)
+	}
+	sign := "0"
+	if temp < 0 {
+		sign = "1"
+	}
+	tenths := int(math.Round(math.Abs(temp)*2) * 5)
+	return Value(fmt.Sprintf("%s%03d", sign, tenths)), nil
+}
+
+//zoneCode returns the WHERE code of the zone with the given name
+func (p *Plant) zoneCode(name string) (string, error) {
+	if strings.ToUpper(name) == CENTRAL.Desc {
+		return CENTRAL.Code, nil
+	}
+	zone, ok := p.Zones[name]
+	if !ok {
+		return "", ErrZoneNotFound
+	}
+	if zone.Num < 1 || zone.Num > 99 {
+		return "", errors.Wrapf(ErrInvalidAddress, "zone %d is not in 1-99", zone.Num)
+	}
+	return strconv.Itoa(zone.Num), nil
+}
+
+//zoneFromCode decodes the WHERE of a thermoregulation frame: Z, #Z (zone through the central unit), #0 (central unit)
+func (p *Plant) zoneFromCode(code string) (Where, error) {
+	if code == "" {
+		return Where{}, nil
+	}
+	code, params := splitParams(code)
+	where := Where{Code: code, Params: params}
+	num, err := strconv.Atoi(strings.TrimPrefix(code, "#"))
+	if err != nil {
+		return Where{}, errors.Wrapf(ErrWhereNotInPlant, "where: %v", code)
+	}
+	if num == 0 {
+		where.Desc = CENTRAL.Desc
+		return where, nil
+	}
+	for kz, z := range p.Zones {
+		if z.Num == num {
+			where.Desc = zonePrefix + kz
+		}
+	}
+	return where, nil
+}
+
+//viaCentral returns the zone WHERE addressed through the central unit (#Z), as required to change it
+func viaCentral(zone Where) Where {
+	if strings.HasPrefix(zone.Code, "#") {
+		return zone
+	}
+	zone.Code = "#" + zone.Code
+	return zone
+}
+
+//Update updates the status with the information carried by a thermoregulation message
+func (z *ZoneStatus) Update(msg Message) error {
+	if msg.Who == nil || msg.Who.Code != whoThermo().Code {
+		return errors.Errorf("not a thermoregulation message: %v", msg)
+	}
+	z.Where = msg.Where
+	if msg.Kind == COMMAND {
+		z.Mode = msg.What.Desc
+		return nil
+	}
+	if msg.Kind != DIMENSIONRESPONSE || len(msg.Values) == 0 {
+		return errors.Errorf("not a thermoregulation status: %v", msg)
+	}
+	var err error
+	switch msg.Dimension {
+	case "0":
+		z.Temperature, err = DecodeTemperature(msg.Values[0])
+	case "14":
+		z.Setpoint, err = DecodeTemperature(msg.Values[0])
+	case "13":
+		z.Offset = thermoOffsets[string(msg.Values[0])]
+	case "19":
+		z.Valves = decodeValues(thermoValves, msg.Values)
+	case "20":
+		z.Actuators = decodeValues(thermoValves, msg.Values)
+	}
+	return err
+}
+
+func decodeValues(codes map[string]string, values []Value) []string {
+	res := make([]string, len(values))
+	for i, v := range values {
+		res[i] = codes[string(v)]
+	}
+	return res
+}
+
+//ZoneTemperature reads the temperature measured in the zone
+func (h *Home) ZoneTemperature(zone Where) (float64, error) {
+	return h.askTemperature(zone, "0")
+}
+
+//ZoneSetpoint reads the target temperature of the zone
+func (h *Home) ZoneSetpoint(zone Where) (float64, error) {
+	return h.askTemperature(zone, "14")
+}
+
+func (h *Home) askTemperature(zone Where, dim Dimension) (float64, error) {
+	answer, err := h.askDimension(whoThermo(), zone, dim)
+	if err != nil {
+		return 0, err
+	}
+	return DecodeTemperature(answer.Values[0])
+}
+
+//ZoneStatus asks the zone all its information: mode, temperatures, offset and valves
+func (h *Home) ZoneStatus(zone Where) (ZoneStatus, error) {
+	status := ZoneStatus{Where: zone}
+	answers, err := h.Ask(NewRequest(whoThermo(), What{}, zone))
+	if err != nil {
+		return status, errors.Wrapf(err, "cannot get status of %s", zone.Desc)
+	}
+	for _, a := range answers {
+		status.Update(a)
+	}
+	return status, nil
+}
+
+//SetZoneSetpoint sets the target temperature of the zone for the given function (SETPOINT_HEATING, SETPOINT_COOLING, SETPOINT_GENERIC)
+func (h *Home) SetZoneSetpoint(zone Where, temp float64, function string) error {
+	t, err := EncodeTemperature(temp)
+	if err != nil {
+		return err
+	}
+	return h.Do(NewDimensionWrite(whoThermo(), viaCentral(zone), "14", t, Value(function)))
+}
+
+//SetZoneMode changes the operating mode of a zone (eg. OFF, PROTECTION, AUTO, MANUAL)
+func (h *Home) SetZoneMode(zone Where, mode string) error {
+	what, err := whoThermo().WhatFromDesc(mode)
+	if err != nil {
+		return errors.Wrapf(err, "unknown thermoregulation mode %s", mode)
+	}
+	return h.Do(NewCommand(whoThermo(), what, viaCentral(zone)))
+}
+
+//SetCentralMode changes the mode of the central unit: heating/cooling, programs, holiday and off modes
+func (h *Home) SetCentralMode(mode string) error {
+	return h.SetZoneMode(CENTRAL, mode)
+}
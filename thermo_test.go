@@ -0,0 +1,108 @@
+package gohome_test
+
+import (
+	"testing"
+
+	"github.com/savardiego/gohome"
+)
+
+func TestDecodeTemperature(t *testing.T) {
+	temps := map[gohome.Value]float64{
+		"0210": 21.0,
+		"0215": 21.5,
+		"1050": -5.0,
+		"0000": 0,
+		"0999": 99.9,
+	}
+	for v, exp := range temps {
+		temp, err := gohome.DecodeTemperature(v)
+		if err != nil || temp != exp {
+			t.Errorf("wrong temperature decoded from %s: %f (err: %v)", v, temp, err)
+		}
+	}
+	for _, v := range []gohome.Value{"", "210", "2210", "02a0", "02100"} {
+		if temp, err := gohome.DecodeTemperature(v); err == nil {
+			t.Errorf("temperature %s should be invalid, decoded as %f", v, temp)
+		}
+	}
+}
+
+func TestEncodeTemperature(t *testing.T) {
+	temps := map[float64]gohome.Value{
+		21.0:  "0210",
+		21.5:  "0215",
+		21.4:  "0215",
+		21.2:  "0210",
+		-5.0:  "1050",
+		-10.5: "1105",
+	}
+	for temp, exp := range temps {
+		v, err := gohome.EncodeTemperature(temp)
+		if err != nil || v != exp {
+			t.Errorf("wrong encoding of temperature %f: %s (err: %v)", temp, v, err)
+		}
+	}
+	if v, err := gohome.EncodeTemperature(120); err == nil {
+		t.Errorf("temperature 120 should be out of range, encoded as %s", v)
+	}
+}
+
+func TestZoneWhere(t *testing.T) {
+	plant := loadTestPlant(t)
+	wheres := map[string]string{
+		"zone:day":     "1",
+		"zone:night":   "12",
+		"zone:central": "#0",
+	}
+	for desc, code := range wheres {
+		w, err := plant.WhereFromDesc(desc)
+		if err != nil || w.Code != code {
+			t.Errorf("wrong zone where for %s: %s (err: %v)", desc, w.Code, err)
+		}
+	}
+	if _, err := plant.WhereFromDesc("zone:garden"); err != gohome.ErrZoneNotFound {
+		t.Errorf("zone:garden should not be found (err: %v)", err)
+	}
+	frames := map[string]string{
+		"*4*303*12##":          "zone:night",
+		"*#4*1*0*0210##":       "zone:day",
+		"*4*311*#0##":          "CENTRAL",
+		"*#4*#12*#14*0210*1##": "zone:night",
+	}
+	for f, desc := range frames {
+		msg := plant.ParseFrame(f)
+		if !msg.IsValid() || msg.Where.Desc != desc {
+			t.Errorf("wrong zone decoded from %s: '%s'", f, msg.Where.Desc)
+		}
+		if msg.Frame() != f {
+			t.Errorf("wrong frame rendered %s, expected was %s", msg.Frame(), f)
+		}
+	}
+}
+
+func TestZoneStatusUpdate(t *testing.T) {
+	plant := loadTestPlant(t)
+	frames := []string{
+		"*4*311*1##",
+		"*#4*1*0*0215##",
+		"*#4*1*14*0200*3##",
+		"*#4*1*13*11##",
+		"*#4*1*19*1*0##",
+		"*#4*1*20*2##",
+	}
+	status := gohome.ZoneStatus{}
+	for _, f := range frames {
+		if err := status.Update(plant.ParseFrame(f)); err != nil {
+			t.Errorf("zone status not updated with %s: %v", f, err)
+		}
+	}
+	if status.Where.Desc != "zone:day" || status.Mode != "AUTO" || status.Temperature != 21.5 || status.Setpoint != 20 || status.Offset != "-1" {
+		t.Errorf("wrong zone status: %+v", status)
+	}
+	if len(status.Valves) != 2 || status.Valves[0] != "ON" || status.Valves[1] != "OFF" || len(status.Actuators) != 1 || status.Actuators[0] != "OPENED" {
+		t.Errorf("wrong valves status: %+v", status)
+	}
+	if err := status.Update(plant.ParseFrame("*1*1*11##")); err == nil {
+		t.Errorf("light message should not update the zone status")
+	}
+}
@@ -0,0 +1,288 @@
+package gohome
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//Problem is an error of the plant configuration, Path is the JSON path of the wrong element in the current (v2)
+//form of the configuration: the lights and shutters of a v1 ambient are reported as ambients.<ambient>.devices.<name>
+type Problem struct {
+	Path string
+	Err  error
+}
+
+//Error returns the problem prefixed by its path
+func (pr Problem) Error() string {
+	return fmt.Sprintf("%s: %v", pr.Path, pr.Err)
+}
+
+//Problems is the error returned when a plant configuration with problems is loaded in strict mode
+type Problems []Problem
+
+//Error returns the problems separated by semicolons
+func (ps Problems) Error() string {
+	msgs := make([]string, len(ps))
+	for i, pr := range ps {
+		msgs[i] = pr.Error()
+	}
+	return "invalid plant configuration: " + strings.Join(msgs, "; ")
+}
+
+//validation collects the problems of a plant and the paths using each WHERE code to find duplicates
+type validation struct {
+	problems Problems
+	codes    map[string][]string
+}
+
+//add records a problem at the JSON path
+func (v *validation) add(path string, err error) {
+	v.problems = append(v.problems, Problem{Path: path, Err: err})
+}
+
+//use records that the element at path has the code, codes are compared inside the same space
+func (v *validation) use(space string, code string, path string) {
+	key := space + " " + code
+	v.codes[key] = append(v.codes[key], path)
+}
+
+//duplicates adds a problem for every element using a code already used by another one
+func (v *validation) duplicates() {
+	for key, paths := range v.codes {
+		if len(paths) < 2 {
+			continue
+		}
+		sort.Strings(paths)
+		code := key[strings.Index(key, " ")+1:]
+		for _, p := range paths[1:] {
+			v.add(p, errors.Errorf("code %s is also used by %s", code, paths[0]))
+		}
+	}
+}
+
+//named is a set of named elements of the plant resolved by a WHERE prefix
+type named struct {
+	path   string
+	prefix string
+	names  []string
+}
+
+//names returns the names of a map of numbered elements
+func names(m map[string]int) []string {
+	n := make([]string, 0, len(m))
+	for k := range m {
+		n = append(n, k)
+	}
+	return n
+}
+
+//namedElements returns the named elements of the plant checked with their resolvers
+func (p *Plant) namedElements() []named {
+	keypads := make([]string, 0, len(p.Keypads))
+	for k := range p.Keypads {
+		keypads = append(keypads, k)
+	}
+	elements := []named{
+		{path: "alarms", prefix: alarmPrefix, names: names(p.Alarms)},
+		{path: "keypads", prefix: keypadPrefix, names: keypads},
+		{path: "meters", prefix: meterPrefix, names: names(p.Meters)},
+		{path: "loads", prefix: loadPrefix, names: names(p.Loads)},
+		{path: "panels", prefix: panelPrefix, names: names(p.Panels)},
+		{path: "locks", prefix: lockPrefix, names: names(p.Locks)},
+		{path: "modules", prefix: modulePrefix, names: names(p.Modules)},
+		{path: "aux", prefix: auxPrefix, names: names(p.Aux)},
+	}
+	if p.Audio != nil {
+		elements = append(elements, named{path: "audio.sources", prefix: sourcePrefix, names: names(p.Audio.Sources)})
+	}
+	return elements
+}
+
+//Validate checks the plant configuration and returns all its problems: missing gateway address, numbers out of
+//the OpenWebNet ranges, unknown references and elements sharing the same WHERE code. A v1 configuration is checked
+//after its migration, so the paths are those of its export with ExportPlant.
+func (p *Plant) Validate() Problems {
+	v := &validation{codes: map[string][]string{}}
+	if p.Address == "" {
+		v.add("address", errors.New("gateway address is empty"))
+	} else if _, _, err := net.SplitHostPort(p.Address); err != nil {
+		v.add("address", errors.Wrapf(err, "gateway address is not <host>:<port>"))
+	}
+	if _, err := ownPassword(p.Password, "1"); p.Password != "" && err != nil {
+		v.add("password", errors.Wrapf(err, "gateway password must be numeric"))
+	}
+	p.validateAmbients(v)
+	p.validateFloors(v)
+	for kg, g := range p.Groups {
+		path := "groups." + kg
+		code, err := groupCode(g.Num)
+		if err != nil {
+			v.add(path+".num", err)
+		} else {
+			v.use("group", code, path+".num")
+		}
+		for i, m := range g.Members {
+			if _, err := p.WhereFromDesc(m); err != nil {
+				v.add(fmt.Sprintf("%s.members[%d]", path, i), errors.Wrapf(err, "member %s", m))
+			}
+		}
+	}
+	for kz, z := range p.Zones {
+		path := "zones." + kz
+		if code, err := p.zoneCode(kz); err != nil {
+			v.add(path+".num", err)
+		} else {
+			v.use("zone", code, path+".num")
+		}
+		for i, a := range z.Ambients {
+			if _, ok := p.Ambients[a]; !ok {
+				v.add(fmt.Sprintf("%s.ambients[%d]", path, i), errors.Wrapf(ErrAmbientNotFound, "ambient %s", a))
+			}
+		}
+	}
+	for _, n := range p.namedElements() {
+		for _, name := range n.names {
+			path := n.path + "." + name
+			code, err := whereResolvers[n.prefix](p, name)
+			if err == nil && code == "" {
+				err = ErrInvalidAddress
+			}
+			if err != nil {
+				v.add(path, err)
+				continue
+			}
+			v.use(n.prefix, code, path)
+		}
+	}
+	p.validateScenarios(v)
+	p.validateAudio(v)
+	v.duplicates()
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return v.problems[i].Path < v.problems[j].Path
+	})
+	return v.problems
+}
+
+//validateAmbients checks the ambients and their devices
+func (p *Plant) validateAmbients(v *validation) {
+	for ka, a := range p.Ambients {
+		path := "ambients." + ka
+		code, err := ambientCode(a.Num)
+		if err != nil {
+			v.add(path+".num", err)
+		} else {
+			v.use("ambient", withParams(code, a.busParams()), path+".num")
+		}
+		for kd, d := range a.Devices {
+			dpath := path + ".devices." + kd
+			if _, ok := deviceDefaults[d.Type]; !ok && d.Who == "" {
+				v.add(dpath+".type", errors.Errorf("unknown device type '%s' without who", d.Type))
+			}
+			if d.Who != "" {
+				if _, err := LookupWho(d.Who); err != nil {
+					v.add(dpath+".who", err)
+				}
+			}
+			if d.Address == "" {
+				v.add(dpath+".address", errors.Wrapf(ErrInvalidAddress, "address is empty"))
+				continue
+			}
+			dcode, err := d.code(a)
+			if err != nil {
+				v.add(dpath+".address", err)
+				continue
+			}
+			if d.isPoint() {
+				v.use("point", withParams(dcode, a.busParams()), dpath+".address")
+			}
+		}
+	}
+}
+
+//validateFloors checks that the ambients of the floors exist and are on a single floor
+func (p *Plant) validateFloors(v *validation) {
+	for kf, f := range p.Floors {
+		path := "floors." + kf
+		if _, ok := p.Ambients[kf]; ok {
+			v.add(path, errors.New("floor has the same name of an ambient"))
+		}
+		if f.Group != "" {
+			if _, ok := p.Groups[f.Group]; !ok {
+				v.add(path+".group", errors.Wrapf(ErrGroupNotFound, "group %s", f.Group))
+			}
+		}
+		for i, a := range f.Ambients {
+			apath := fmt.Sprintf("%s.ambients[%d]", path, i)
+			if _, ok := p.Ambients[a]; !ok {
+				v.add(apath, errors.Wrapf(ErrAmbientNotFound, "ambient %s", a))
+				continue
+			}
+			v.use("floor", a, apath)
+		}
+	}
+}
+
+//validateScenarios checks the scenarios, that are numbered 1-16 in their module or 1-300 in the MH200N
+func (p *Plant) validateScenarios(v *validation) {
+	for ks, s := range p.Scenarios {
+		path := "scenarios." + ks
+		if s.Module == "" {
+			code, err := p.scenarioCode(ks)
+			if err != nil {
+				v.add(path+".num", err)
+				continue
+			}
+			v.use("scenario", code, path+".num")
+			continue
+		}
+		if _, ok := p.Modules[s.Module]; !ok {
+			v.add(path+".module", errors.Wrapf(ErrScenarioNotFound, "module %s", s.Module))
+		}
+		if s.Num < 1 || s.Num > 16 {
+			v.add(path+".num", errors.Wrapf(ErrInvalidAddress, "scenario %d is not in 1-16", s.Num))
+			continue
+		}
+		v.use("scenario", fmt.Sprintf("%s#%d", s.Module, s.Num), path+".num")
+	}
+}
+
+//validateAudio checks the zones and the speakers of the sound diffusion
+func (p *Plant) validateAudio(v *validation) {
+	if p.Audio == nil {
+		return
+	}
+	for kz, z := range p.Audio.Zones {
+		path := "audio.zones." + kz
+		if code, err := p.audioCode(kz); err != nil {
+			v.add(path+".area", err)
+		} else {
+			v.use(audioPrefix, code, path+".area")
+		}
+		for ks := range z.Speakers {
+			spath := path + ".speakers." + ks
+			code, err := p.audioCode(kz + "." + ks)
+			if err != nil {
+				v.add(spath, err)
+				continue
+			}
+			v.use(audioPrefix, code, spath)
+		}
+	}
+}
+
+//NewStrictPlant loads a plant configuration like NewPlant, but fails if the configuration has any problem
+func NewStrictPlant(config io.Reader) (*Plant, error) {
+	plant, err := decodePlant(config)
+	if err != nil {
+		return nil, err
+	}
+	if problems := plant.Validate(); len(problems) > 0 {
+		return nil, errors.WithStack(problems)
+	}
+	return plant, nil
+}
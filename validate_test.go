@@ -0,0 +1,82 @@
+package gohome_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/savardiego/gohome"
+)
+
+func TestValidatePlant(t *testing.T) {
+	for _, file := range []string{"testdata/casa.json", "testdata/casa_v2.json"} {
+		config, err := os.Open(file)
+		if err != nil {
+			t.Fatalf("Cannot open %s: %v", file, err)
+		}
+		plant, err := gohome.NewStrictPlant(config)
+		config.Close()
+		if err != nil {
+			t.Errorf("%s should be valid: %v", file, err)
+			continue
+		}
+		if problems := plant.Validate(); len(problems) > 0 {
+			t.Errorf("%s should have no problems: %v", file, problems)
+		}
+	}
+}
+
+func TestValidateProblems(t *testing.T) {
+	config := `{
+  "name": "wrong",
+  "ambients": {
+    "kitchen": {"num": 1, "lights": {"main": 2, "table": 2}},
+    "living": {"num": 1},
+    "attic": {"num": 11},
+    "studio": {"num": 3, "devices": {"desk": {"type": "light", "address": ""}}}
+  },
+  "groups": {"all": {"num": 300, "members": ["cellar"]}},
+  "aux": {"siren": 12}
+}`
+	expected := []string{
+		"address",
+		"ambients.attic.num",
+		"ambients.kitchen.devices.table.address",
+		"ambients.living.num",
+		"ambients.studio.devices.desk.address",
+		"aux.siren",
+		"groups.all.members[0]",
+		"groups.all.num",
+	}
+	plant, err := gohome.NewPlant(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("Plant not loaded: %v", err)
+	}
+	problems := plant.Validate()
+	paths := make([]string, len(problems))
+	for i, pr := range problems {
+		paths[i] = pr.Path
+	}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("wrong problems: %v", problems)
+	}
+	_, err = gohome.NewStrictPlant(strings.NewReader(config))
+	if strict, ok := errors.Cause(err).(gohome.Problems); !ok || len(strict) != len(expected) {
+		t.Errorf("strict load should fail with the problems: %v", err)
+	}
+}
+
+func TestValidateAuxDevice(t *testing.T) {
+	config := `{"address": "192.168.0.35:20000", "ambients": {"hall": {"num": 1, "devices": {
+  "lamp": {"type": "light", "address": "3"},
+  "bell": {"type": "relay", "who": "9", "address": "3"}
+}}}}`
+	plant, err := gohome.NewPlant(strings.NewReader(config))
+	if err != nil {
+		t.Fatalf("Plant not loaded: %v", err)
+	}
+	if problems := plant.Validate(); len(problems) > 0 {
+		t.Errorf("aux channel is not a point of the ambient: %v", problems)
+	}
+}
@@ -1,73 +1,322 @@
 package gohome
 
 import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
 )
 
+//Who is a function of the plant with its WHATs and dimensions. Values lists the types of the values
+//of each dimension, Where the forms of WHERE the WHO accepts (eg. ambient, point, group, zone).
 type Who struct {
-	Code    string
-	Desc    string
-	Actions map[string]string
-}
-
-var actions_1 = map[string]string{
-	"0":    "TURN_OFF",
-	"1":    "TURN_ON",
-	"2":    "SET_20",
-	"3":    "SET_30",
-	"4":    "SET_40",
-	"5":    "SET_50",
-	"6":    "SET_60",
-	"7":    "SET_70",
-	"8":    "SET_80",
-	"9":    "SET_90",
-	"10":   "SET_100",
-	"11":   "ON_1_MIN",
-	"12":   "ON_2_MIN",
-	"13":   "ON_3_MIN",
-	"14":   "ON_4_MIN",
-	"15":   "ON_5_MIN",
-	"16":   "ON_15_MIN",
-	"17":   "ON_30_SEC",
-	"18":   "ON_0_5_SEC",
-	"20":   "BLINK_ON_0_5_SEC",
-	"21":   "BINK_ON_1_SEC",
-	"22":   "BLINK_ON_1_5_SEC",
-	"23":   "BLINK_ON_2_SEC",
-	"24":   "BLINK_ON_2_5_SEC",
-	"25":   "BLINK_ON_3_SEC",
-	"26":   "BLINK_OM_3_5_SEC",
-	"27":   "BLINK_ON_4_SEC",
-	"28":   "BLINK_ON_4_5_SEC",
-	"29":   "BLINK_ON_5_SEC",
-	"30":   "UP_ONE_LEVEL",
-	"31":   "DOWN_ONE_LEVEL",
-	"1000": "JOLLY",
-}
-
-var whoNone = &Who{Code: "", Desc: "", Actions: map[string]string{}}
-var whoLight = &Who{Code: "1", Desc: "LIGHT", Actions: actions_1}
-
-var allWho = map[string]*Who{
-	"1":     whoLight,
-	"LIGHT": whoLight,
+	Code       string
+	Desc       string
+	Actions    map[string]string
+	Dimensions map[string]string
+	Values     map[string][]string
+	Where      []string
+	ReadOnly   bool
 }
 
-func NewWho(who string) *Who {
-	w, ok := allWho[who]
+//go:embed data/who.json
+var defaultWhoData []byte
+
+//whoSpec is a WHO as it is described in the catalogue files
+type whoSpec struct {
+	Code       string                   `json:"code"`
+	Desc       string                   `json:"desc"`
+	ReadOnly   bool                     `json:"readOnly,omitempty"`
+	Where      []string                 `json:"where,omitempty"`
+	What       map[string]string        `json:"what,omitempty"`
+	Dimensions map[string]dimensionSpec `json:"dimensions,omitempty"`
+}
+
+//dimensionSpec is a dimension with the types of its values (eg. level, speed, temperature)
+type dimensionSpec struct {
+	Desc   string   `json:"desc"`
+	Values []string `json:"values,omitempty"`
+}
+
+//registry holds the known WHOs keyed by code and by description
+var registry = struct {
+	sync.RWMutex
+	whos map[string]*Who
+}{whos: defaultWhos()}
+
+//defaultWhos decodes the embedded catalogue, it panics as a broken default catalogue is a build error
+func defaultWhos() map[string]*Who {
+	specs, err := decodeWhoSpecs(bytes.NewReader(defaultWhoData))
+	if err != nil {
+		panic(fmt.Sprintf("wrong embedded WHO catalogue: %v", err))
+	}
+	whos := make(map[string]*Who, 2*len(specs))
+	for _, s := range specs {
+		w := newWhoFromSpec(s)
+		whos[w.Code] = w
+		whos[w.Desc] = w
+	}
+	return whos
+}
+
+//embeddedWhos are the WHOs of the embedded catalogue
+var embeddedWhos = defaultWhos()
+
+//defaultWho returns the registered WHO with the code, so that the package uses the WHOs of the catalogue loaded
+//with LoadWhoCatalogue or RegisterWho, or the one of the embedded catalogue if missing
+func defaultWho(code string) *Who {
+	if w, ok := registeredWho(code); ok && w.Code == code {
+		return w
+	}
+	w, ok := embeddedWhos[code]
 	if !ok {
+		panic(fmt.Sprintf("WHO %s missing in the embedded catalogue", code))
+	}
+	return w
+}
+
+func whoScenario() *Who            { return defaultWho("0") }
+func whoLight() *Who               { return defaultWho("1") }
+func whoAutomation() *Who          { return defaultWho("2") }
+func whoLoad() *Who                { return defaultWho("3") }
+func whoThermo() *Who              { return defaultWho("4") }
+func whoAlarm() *Who               { return defaultWho("5") }
+func whoDoorEntry() *Who           { return defaultWho("6") }
+func whoAux() *Who                 { return defaultWho("9") }
+func whoGateway() *Who             { return defaultWho("13") }
+func whoCEN() *Who                 { return defaultWho("15") }
+func whoSound() *Who               { return defaultWho("16") }
+func whoScenarioProgramming() *Who { return defaultWho("17") }
+func whoEnergy() *Who              { return defaultWho("18") }
+func whoSoundSystem() *Who         { return defaultWho("22") }
+func whoCENPlus() *Who             { return defaultWho("25") }
+func whoAutomationDiag() *Who      { return defaultWho("1001") }
+func whoThermoDiag() *Who          { return defaultWho("1004") }
+func whoGatewayDiag() *Who         { return defaultWho("1013") }
+
+//newWhoFromSpec converts a catalogue entry to a WHO
+func newWhoFromSpec(s whoSpec) *Who {
+	w := &Who{Code: s.Code, Desc: strings.ToUpper(s.Desc), ReadOnly: s.ReadOnly, Where: s.Where,
+		Actions: map[string]string{}, Dimensions: map[string]string{}, Values: map[string][]string{}}
+	for k, v := range s.What {
+		w.Actions[k] = strings.ToUpper(v)
+	}
+	for k, d := range s.Dimensions {
+		w.Dimensions[k] = strings.ToUpper(d.Desc)
+		if len(d.Values) > 0 {
+			w.Values[k] = d.Values
+		}
+	}
+	return w
+}
+
+//merge adds to the WHO the WHATs, dimensions and WHERE forms of the catalogue entry, replacing the ones with the same code
+func (w *Who) merge(s whoSpec) {
+	n := newWhoFromSpec(s)
+	if n.Desc != "" {
+		w.Desc = n.Desc
+	}
+	w.ReadOnly = w.ReadOnly || n.ReadOnly
+	if len(n.Where) > 0 {
+		w.Where = n.Where
+	}
+	for k, v := range n.Actions {
+		w.Actions[k] = v
+	}
+	for k, v := range n.Dimensions {
+		w.Dimensions[k] = v
+		delete(w.Values, k)
+	}
+	for k, v := range n.Values {
+		w.Values[k] = v
+	}
+}
+
+//LoadWhoCatalogue reads a JSON list of WHOs: new WHOs are registered, known ones are extended and their entries overridden
+func LoadWhoCatalogue(r io.Reader) error {
+	specs, err := decodeWhoSpecs(r)
+	if err != nil {
+		return err
+	}
+	for i, s := range specs {
+		known, err := LookupWho(s.Code)
+		if err != nil {
+			if err := RegisterWho(newWhoFromSpec(s)); err != nil {
+				return errors.Wrapf(err, "wrong WHO at position %d", i)
+			}
+			continue
+		}
+		w := *known
+		w.Actions, w.Dimensions, w.Values = copyMap(known.Actions), copyMap(known.Dimensions), map[string][]string{}
+		for k, v := range known.Values {
+			w.Values[k] = v
+		}
+		w.merge(s)
+		if err := RegisterWho(&w); err != nil {
+			return errors.Wrapf(err, "wrong WHO at position %d", i)
+		}
+	}
+	return nil
+}
+
+func decodeWhoSpecs(r io.Reader) ([]whoSpec, error) {
+	var specs []whoSpec
+	if err := json.NewDecoder(r).Decode(&specs); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode WHO catalogue")
+	}
+	return specs, nil
+}
+
+func copyMap(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+//RegisterWho adds the WHO to the catalogue. A WHO with the same code is replaced: the values already returned
+//by LookupWho are not changed, look the WHO up again to get the new one.
+func RegisterWho(w *Who) error {
+	if w == nil || w.Desc == "" {
+		return errors.Wrapf(ErrWhoNotFound, "WHO without description")
+	}
+	if _, err := strconv.Atoi(w.Code); err != nil {
+		return errors.Wrapf(ErrWhoNotFound, "WHO code '%s' is not a number", w.Code)
+	}
+	if w.Actions == nil {
+		w.Actions = map[string]string{}
+	}
+	if w.Dimensions == nil {
+		w.Dimensions = map[string]string{}
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if other, ok := registry.whos[w.Desc]; ok && other.Code != w.Code {
+		return errors.Errorf("description %s already used by WHO %s", w.Desc, other.Code)
+	}
+	if old, ok := registry.whos[w.Code]; ok {
+		delete(registry.whos, old.Desc)
+	}
+	registry.whos[w.Code] = w
+	registry.whos[w.Desc] = w
+	return nil
+}
+
+//UnregisterWho removes the WHO with the given code from the catalogue, a WHO of the embedded catalogue is
+//restored to its default
+func UnregisterWho(code string) error {
+	registry.Lock()
+	defer registry.Unlock()
+	old, ok := registry.whos[code]
+	if !ok || old.Code != code {
+		return errors.Wrapf(ErrWhoNotFound, "WHO %s", code)
+	}
+	delete(registry.whos, old.Code)
+	delete(registry.whos, old.Desc)
+	if def, ok := embeddedWhos[code]; ok {
+		registry.whos[def.Code] = def
+		registry.whos[def.Desc] = def
+	}
+	return nil
+}
+
+//LookupWho returns the WHO with the given code, description or name in any locale, ignoring case
+func LookupWho(who string) (*Who, error) {
+	if w, ok := registeredWho(strings.ToUpper(who)); ok {
+		return w, nil
+	}
+	if desc, ok := whoFromAlias(who); ok {
+		if w, ok := registeredWho(desc); ok {
+			return w, nil
+		}
+	}
+	return nil, errors.Wrapf(ErrWhoNotFound, "WHO %s", who)
+}
+
+func registeredWho(key string) (*Who, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	w, ok := registry.whos[key]
+	return w, ok
+}
+
+//Whos returns the registered WHOs sorted by code
+func Whos() []*Who {
+	registry.RLock()
+	defer registry.RUnlock()
+	whos := make([]*Who, 0, len(registry.whos)/2)
+	for k, w := range registry.whos {
+		if k == w.Code {
+			whos = append(whos, w)
+		}
+	}
+	sort.Slice(whos, func(i, j int) bool {
+		a, _ := strconv.Atoi(whos[i].Code)
+		b, _ := strconv.Atoi(whos[j].Code)
+		return a < b
+	})
+	return whos
+}
+
+//whoNone is returned by NewWho for an unknown WHO, it has no code and the messages with it are refused
+var whoNone = &Who{Desc: "NONE", Actions: map[string]string{}, Dimensions: map[string]string{}}
+
+//NewWho returns the WHO with the given code or description. An unknown WHO is logged and returned
+//without code, WHATs and dimensions, so that it cannot be sent.
+//
+//Deprecated: use LookupWho, that returns an error for an unknown WHO.
+func NewWho(who string) *Who {
+	w, err := LookupWho(who)
+	if err != nil {
+		log.Printf("NewWho: unknown WHO '%s'", who)
 		return whoNone
 	}
 	return w
 }
 
+//accepts returns true if the WHO accepts the form of WHERE (eg. ambient, point, group, zone)
+func (w *Who) accepts(form string) bool {
+	for _, f := range w.Where {
+		if f == form {
+			return true
+		}
+	}
+	return false
+}
+
+//WhatFromDesc returns the WHAT with the given description or name in any locale, ignoring case.
+//Parameters may follow the description: <what>[#P1[#P2..]]
 func (w *Who) WhatFromDesc(text string) (What, error) {
+	desc, params := splitParams(text)
+	desc = strings.ToUpper(desc)
+	if canonical, ok := whatFromAlias(w.Desc, desc); ok {
+		if _, err := w.whatCode(desc); err != nil {
+			desc = canonical
+		}
+	}
+	code, err := w.whatCode(desc)
+	if err != nil {
+		return What{}, err
+	}
+	return What{Code: code, Desc: desc, Params: params}, nil
+}
+
+func (w *Who) whatCode(desc string) (string, error) {
 	for k, v := range w.Actions {
-		if v == strings.ToUpper(text) {
-			return What{Code: k, Desc: v}, nil
+		if v == desc {
+			return k, nil
 		}
 	}
-	return What{}, ErrWhatNotFound
+	return "", ErrWhatNotFound
 }
 
 func (w *Who) WhatFromCode(code string) (What, error) {
@@ -77,3 +326,13 @@ func (w *Who) WhatFromCode(code string) (What, error) {
 	}
 	return What{Code: code, Desc: desc}, nil
 }
+
+//DimensionFromDesc returns the code of the dimension with the given description
+func (w *Who) DimensionFromDesc(text string) (Dimension, error) {
+	for k, v := range w.Dimensions {
+		if v == strings.ToUpper(text) {
+			return Dimension(k), nil
+		}
+	}
+	return "", ErrDimensionNotFound
+}
@@ -0,0 +1,122 @@
+package gohome_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/savardiego/gohome"
+)
+
+func TestLookupWho(t *testing.T) {
+	for _, w := range []string{"1", "LIGHT", "light", "1013"} {
+		if _, err := gohome.LookupWho(w); err != nil {
+			t.Errorf("WHO %s not found: %v", w, err)
+		}
+	}
+	if w, err := gohome.LookupWho("7777"); errors.Cause(err) != gohome.ErrWhoNotFound {
+		t.Errorf("unknown WHO should not be found: %v %v", w, err)
+	}
+	light, _ := gohome.LookupWho("LIGHT")
+	for _, what := range []string{"BLINK_ON_1_SEC", "BLINK_ON_3_5_SEC"} {
+		if _, err := light.WhatFromDesc(what); err != nil {
+			t.Errorf("what %s not found: %v", what, err)
+		}
+	}
+	if len(light.Values["1"]) != 2 || len(light.Where) == 0 {
+		t.Errorf("wrong light catalogue: values %v where %v", light.Values, light.Where)
+	}
+	if len(gohome.Whos()) < 18 {
+		t.Errorf("missing WHOs in the catalogue: %d", len(gohome.Whos()))
+	}
+}
+
+func TestRegisterWho(t *testing.T) {
+	who := &gohome.Who{Code: "99", Desc: "TEST_DEVICE", Actions: map[string]string{"0": "OFF"}}
+	if err := gohome.RegisterWho(who); err != nil {
+		t.Fatalf("WHO not registered: %v", err)
+	}
+	defer gohome.UnregisterWho("99")
+	if w, err := gohome.LookupWho("TEST_DEVICE"); err != nil || w.Code != "99" {
+		t.Errorf("registered WHO not found: %v %v", w, err)
+	}
+	if err := gohome.RegisterWho(&gohome.Who{Code: "98", Desc: "TEST_DEVICE"}); err == nil {
+		t.Errorf("description of another WHO should not be registered")
+	}
+	if err := gohome.RegisterWho(&gohome.Who{Code: "x", Desc: "WRONG"}); err == nil {
+		t.Errorf("WHO with wrong code should not be registered")
+	}
+	catalogue := `[{"code": "99", "what": {"1": "on"}, "dimensions": {"5": {"desc": "power", "values": ["watt"]}}}]`
+	if err := gohome.LoadWhoCatalogue(strings.NewReader(catalogue)); err != nil {
+		t.Fatalf("catalogue not loaded: %v", err)
+	}
+	if _, err := who.WhatFromDesc("ON"); err == nil {
+		t.Errorf("WHO already looked up should not be changed")
+	}
+	who, err := gohome.LookupWho("99")
+	if err != nil {
+		t.Fatalf("extended WHO not found: %v", err)
+	}
+	if _, err := who.WhatFromDesc("ON"); err != nil {
+		t.Errorf("WHO not extended: %v", err)
+	}
+	if _, err := who.WhatFromDesc("OFF"); err != nil || who.Desc != "TEST_DEVICE" {
+		t.Errorf("WHO entries lost: %v %v", who, err)
+	}
+	if dim, err := who.DimensionFromDesc("POWER"); err != nil || who.Values[string(dim)][0] != "watt" {
+		t.Errorf("dimension not added: %v", err)
+	}
+	if err := gohome.LoadWhoCatalogue(strings.NewReader(`{"code": "99"}`)); err == nil {
+		t.Errorf("wrong catalogue should not be loaded")
+	}
+}
+
+func TestUnregisterWho(t *testing.T) {
+	if err := gohome.RegisterWho(&gohome.Who{Code: "97", Desc: "TEST_UNREGISTER"}); err != nil {
+		t.Fatalf("WHO not registered: %v", err)
+	}
+	if err := gohome.UnregisterWho("97"); err != nil {
+		t.Errorf("WHO not unregistered: %v", err)
+	}
+	if w, err := gohome.LookupWho("TEST_UNREGISTER"); err == nil {
+		t.Errorf("unregistered WHO still found: %v", w)
+	}
+	if err := gohome.RegisterWho(&gohome.Who{Code: "1", Desc: "LIGHT", Actions: map[string]string{"1": "ON"}}); err != nil {
+		t.Fatalf("WHO not replaced: %v", err)
+	}
+	if err := gohome.UnregisterWho("1"); err != nil {
+		t.Errorf("WHO not unregistered: %v", err)
+	}
+	if w, err := gohome.LookupWho("LIGHT"); err != nil || len(w.Actions) < 2 {
+		t.Errorf("default WHO not restored: %v %v", w, err)
+	}
+	if err := gohome.UnregisterWho("LIGHT"); err == nil {
+		t.Errorf("WHO should be unregistered only by code")
+	}
+}
+
+func TestUnknownWho(t *testing.T) {
+	who := gohome.NewWho("LIGTH")
+	if who.Code != "" {
+		t.Errorf("unknown WHO should have no code: %+v", who)
+	}
+	h := gohome.NewHome(loadTestPlant(t))
+	if err := h.Do(gohome.NewCommand(who, gohome.What{Code: "0"}, gohome.GENERAL)); errors.Cause(err) != gohome.ErrWhoNotFound {
+		t.Errorf("command of unknown WHO should be refused: %v", err)
+	}
+	if _, err := h.Ask(gohome.NewRequest(who, gohome.What{}, gohome.GENERAL)); errors.Cause(err) != gohome.ErrWhoNotFound {
+		t.Errorf("request of unknown WHO should be refused: %v", err)
+	}
+}
+
+func TestCatalogueOverride(t *testing.T) {
+	plant := loadTestPlant(t)
+	if err := gohome.LoadWhoCatalogue(strings.NewReader(`[{"code": "0", "what": {"3": "movie"}}]`)); err != nil {
+		t.Fatalf("catalogue not loaded: %v", err)
+	}
+	defer gohome.UnregisterWho("0")
+	cmd, err := plant.ScenarioCommand("cinema", "START_SCENARIO")
+	if err != nil || cmd.What.Desc != "MOVIE" {
+		t.Errorf("loaded catalogue not used by the scenarios: %+v (err: %v)", cmd.What, err)
+	}
+}